@@ -1,31 +1,161 @@
 package agify
 
 import (
-	"encoding/json"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// Version is the version of this library
+	Version = "0.1.0"
+
+	// defaultUserAgent is the User-Agent header sent when none is configured
+	defaultUserAgent = "agify-go/" + Version
+
+	// defaultMaxResponseBytes is the largest response body read when no limit is configured
+	defaultMaxResponseBytes = 1 << 20
+
+	// defaultApiKeyEnv is the environment variable read by WithApiKeyFromEnv when none is given
+	defaultApiKeyEnv = "AGIFY_API_KEY"
 )
 
 type (
 	// Client is the client to call agify.io
 	Client struct {
-		apiKey  string
-		baseUrl string
-		http    *http.Client
+		http      *http.Client
+		chunkSize int
+
+		maxRetries        int
+		retryBaseDelay    time.Duration
+		retryServerErrors bool
+		userAgent         string
+		timeout           time.Duration
+		dedup             bool
+		chunkSlots        *semaphore
+		skipEmpty         bool
+		headers           http.Header
+
+		cache         *predictionCache
+		compression   bool
+		requestHooks  []RequestHook
+		responseHooks []ResponseHook
+		rateLimitWait bool
+		tracer        trace.Tracer
+
+		// configErr is an invalid option that is reported on the first request
+		configErr           error
+		breaker             *circuitBreaker
+		serviceUrls         map[Service]string
+		strictDecoding      bool
+		maxResponseBytes    int64
+		normalizeNames      bool
+		restoreNames        bool
+		apiKeys             *apiKeyPool
+		chunkTimeout        time.Duration
+		metrics             MetricsRecorder
+		clock               Clock
+		calls               *callGroup
+		retryBudget         *retryBudget
+		limiter             *requestLimiter
+		params              ParamConfig
+		logger              *slog.Logger
+		jitter              *jitterSource
+		fallbackUrl         string
+		defaultCountry      string
+		validateCountry     bool
+		notFoundAsNil       bool
+		etags               *etagStore
+		retryPolicy         RetryPolicy
+		ndjsonErrorLines    bool
+		maxBatchConcurrency int
+		quotaAwarePacing    bool
+		collectErrors       bool
+		inFlight            *semaphore
 	}
 
 	// clientDefaults is a struct used to hold the default values for the client
 	clientDefaults struct {
-		apiKey  string
-		baseUrl string
-		http    *http.Client
+		apiKey    string
+		http      *http.Client
+		chunkSize int
+
+		maxRetries          int
+		retryBaseDelay      time.Duration
+		retryServerErrors   bool
+		userAgent           string
+		userAgentSuffix     string
+		timeout             time.Duration
+		dedup               bool
+		concurrency         int
+		skipEmpty           bool
+		headers             http.Header
+		cacheTTL            time.Duration
+		compression         bool
+		requestHooks        []RequestHook
+		responseHooks       []ResponseHook
+		rateLimitWait       bool
+		tracerProvider      trace.TracerProvider
+		proxyUrl            string
+		breakerThreshold    int
+		breakerCooldown     time.Duration
+		serviceUrls         map[Service]string
+		strictDecoding      bool
+		maxResponseBytes    int64
+		normalizeNames      bool
+		restoreNames        bool
+		apiKeys             []string
+		skipLimitedApiKeys  bool
+		chunkTimeout        time.Duration
+		metrics             MetricsRecorder
+		clock               Clock
+		coalescing          bool
+		redirectPolicy      func(*http.Request, []*http.Request) error
+		params              ParamConfig
+		logger              *slog.Logger
+		jitterSource        rand.Source
+		fallbackUrl         string
+		transportTuning     *transportTuning
+		defaultCountry      string
+		validateCountry     bool
+		notFoundAsNil       bool
+		retryBudgetRatio    float64
+		conditionalRequests bool
+		tlsConfig           *tls.Config
+		retryPolicy         RetryPolicy
+		rateLimiterRps      float64
+		rateLimiterBurst    int
+		ndjsonErrorLines    bool
+		maxBatchConcurrency int
+		network             string
+		quotaAwarePacing    bool
+		collectErrors       bool
+		maxInFlight         int
 	}
 
 	// ClientOption is a function that can be used to configure the client
 	ClientOption func(*clientDefaults)
 
+	// RequestHook is called before a request is sent
+	RequestHook func(*http.Request)
+
+	// ResponseHook is called after a response is received with the latency of the request
+	ResponseHook func(*http.Response, time.Duration)
+
 	// Prediction is the age prediction for a name
 	Prediction struct {
 		// Name is the name that was queried
@@ -36,25 +166,51 @@ type (
 		Count int `json:"count"`
 		// Country is the country that was queried
 		Country string `json:"country_id"`
+		// Found is true when the API returned an age, as an unknown name has a null age
+		Found bool `json:"-"`
+		// Extra holds any fields in the response that are not mapped to the fields above
+		Extra map[string]any `json:"-"`
+		// Candidates are the alternative ages with their probabilities, sorted by probability descending, when the API returns them
+		Candidates []AgeCandidate `json:"candidates,omitempty"`
 	}
 
-	// RateLimit is the rate limiting information from the API
-	RateLimit struct {
-		Limit     string
-		Remaining string
-		Reset     string
+	// AgeCandidate is a possible age for a name along with its probability
+	AgeCandidate struct {
+		// Age is the candidate age
+		Age int `json:"age"`
+		// Probability is the probability of the age between 0 and 1
+		Probability float64 `json:"probability"`
 	}
 
-	// errorResponse is the error response from the agify API
-	errorResponse struct {
-		Error string `json:"error"`
+	// ResponseMeta is the metadata about the response from the API
+	ResponseMeta struct {
+		// RateLimit is the rate limiting information from the response
+		RateLimit *RateLimit
+		// Latency is the time taken by the HTTP round trip, excluding decoding
+		Latency time.Duration
+		// StatusCode is the HTTP status code of the response
+		StatusCode int
+
+		// response is the HTTP response with its body replaced by the bytes already read
+		response *http.Response
+		// body is the bytes read from the response, kept for every status code so the body can be replayed
+		body []byte
+		// receivedAt is the time the response was received
+		receivedAt time.Time
 	}
 )
 
-// WithApiKey overrides the default API key
+// WithUrl overrides the default agify.io API URL
 func WithUrl(baseUrl string) ClientOption {
 	return func(client *clientDefaults) {
-		client.baseUrl = baseUrl
+		client.serviceUrls[ServiceAgify] = baseUrl
+	}
+}
+
+// WithServiceURL overrides the default API URL for a service
+func WithServiceURL(service Service, url string) ClientOption {
+	return func(client *clientDefaults) {
+		client.serviceUrls[service] = url
 	}
 }
 
@@ -62,6 +218,36 @@ func WithUrl(baseUrl string) ClientOption {
 func WithApiKey(apiKey string) ClientOption {
 	return func(client *clientDefaults) {
 		client.apiKey = apiKey
+		client.apiKeys = nil
+	}
+}
+
+// WithApiKeyFromEnv reads the API key from the environment variable when the client is created
+// The variable defaults to AGIFY_API_KEY, and an unset or empty variable leaves the API key unchanged.
+func WithApiKeyFromEnv(envVar string) ClientOption {
+	if envVar == "" {
+		envVar = defaultApiKeyEnv
+	}
+
+	return func(client *clientDefaults) {
+		if apiKey := os.Getenv(envVar); apiKey != "" {
+			WithApiKey(apiKey)(client)
+		}
+	}
+}
+
+// WithApiKeys rotates through the API keys round-robin on each request
+func WithApiKeys(apiKeys ...string) ClientOption {
+	return func(client *clientDefaults) {
+		client.apiKey = ""
+		client.apiKeys = apiKeys
+	}
+}
+
+// WithSkipLimitedApiKeys skips an API key that was rate limited until its rate limit window resets
+func WithSkipLimitedApiKeys(skipLimitedApiKeys bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.skipLimitedApiKeys = skipLimitedApiKeys
 	}
 }
 
@@ -72,134 +258,959 @@ func WithClient(httpClient *http.Client) ClientOption {
 	}
 }
 
+// WithChunkSize overrides the number of names sent in each batch request
+func WithChunkSize(chunkSize int) ClientOption {
+	return func(client *clientDefaults) {
+		client.chunkSize = chunkSize
+	}
+}
+
+// WithRetry retries rate limited requests up to maxRetries times
+// The delay between attempts grows exponentially from baseDelay unless the API reports when the rate limit resets.
+func WithRetry(maxRetries int, baseDelay time.Duration) ClientOption {
+	return func(client *clientDefaults) {
+		client.maxRetries = maxRetries
+		client.retryBaseDelay = baseDelay
+	}
+}
+
+// WithRetryServerErrors also retries requests that fail with a 5xx status code
+func WithRetryServerErrors(retryServerErrors bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.retryServerErrors = retryServerErrors
+	}
+}
+
+// WithUserAgent overrides the default User-Agent header
+func WithUserAgent(userAgent string) ClientOption {
+	return func(client *clientDefaults) {
+		client.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets a deadline for each request
+// A zero duration means no timeout. A sooner deadline on the request context takes precedence.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(client *clientDefaults) {
+		client.timeout = timeout
+	}
+}
+
+// WithDedup removes case-insensitive duplicate names from batch requests
+// The results are fanned back out so they match the order and length of the input.
+func WithDedup(dedup bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.dedup = dedup
+	}
+}
+
+// WithConcurrency overrides the number of batch chunks the client requests in parallel, shared across every concurrent batch
+// Use WithMaxBatchConcurrency to limit how many of those chunks a single batch can take.
+func WithConcurrency(concurrency int) ClientOption {
+	return func(client *clientDefaults) {
+		client.concurrency = concurrency
+	}
+}
+
+// WithSkipEmpty skips empty names in batch requests instead of rejecting the whole batch
+func WithSkipEmpty(skipEmpty bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.skipEmpty = skipEmpty
+	}
+}
+
+// WithHeader adds a header to every request
+// Calling it multiple times appends values rather than overwriting them.
+func WithHeader(key string, value string) ClientOption {
+	return func(client *clientDefaults) {
+		if client.headers == nil {
+			client.headers = http.Header{}
+		}
+
+		client.headers.Add(key, value)
+	}
+}
+
+// WithCache caches single name predictions for the duration of the ttl
+// Expired entries are evicted by a background goroutine that is stopped by Close.
+func WithCache(ttl time.Duration) ClientOption {
+	return func(client *clientDefaults) {
+		client.cacheTTL = ttl
+	}
+}
+
+// WithNationalizeUrl overrides the default nationalize.io API URL
+func WithNationalizeUrl(nationalizeUrl string) ClientOption {
+	return func(client *clientDefaults) {
+		client.serviceUrls[ServiceNationalize] = nationalizeUrl
+	}
+}
+
+// WithGenderizeUrl overrides the default genderize.io API URL
+func WithGenderizeUrl(genderizeUrl string) ClientOption {
+	return func(client *clientDefaults) {
+		client.serviceUrls[ServiceGenderize] = genderizeUrl
+	}
+}
+
+// WithCompression requests gzip compressed responses and decompresses them
+func WithCompression(compression bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.compression = compression
+	}
+}
+
+// WithRequestHook adds a hook that is called before each request is sent
+// Hooks are called in the order they are registered.
+func WithRequestHook(hook RequestHook) ClientOption {
+	return func(client *clientDefaults) {
+		client.requestHooks = append(client.requestHooks, hook)
+	}
+}
+
+// WithResponseHook adds a hook that is called with each response and its latency
+// Hooks are called in the order they are registered, and each can read the response body.
+func WithResponseHook(hook ResponseHook) ClientOption {
+	return func(client *clientDefaults) {
+		client.responseHooks = append(client.responseHooks, hook)
+	}
+}
+
+// WithRateLimitWait waits for the rate limit window to reset and retries when a request is rate limited
+// The wait uses the Retry-After or X-Rate-Reset header and is cut short if the context is done.
+func WithRateLimitWait(rateLimitWait bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.rateLimitWait = rateLimitWait
+	}
+}
+
+// WithTracerProvider records an OpenTelemetry span for each request using the provider
+func WithTracerProvider(tracerProvider trace.TracerProvider) ClientOption {
+	return func(client *clientDefaults) {
+		client.tracerProvider = tracerProvider
+	}
+}
+
+// WithProxy sends requests through the proxy at proxyUrl
+// The transport of the http client is cloned rather than modified. An invalid URL is returned from the first request.
+func WithProxy(proxyUrl string) ClientOption {
+	return func(client *clientDefaults) {
+		client.proxyUrl = proxyUrl
+	}
+}
+
+// WithCircuitBreaker fails requests fast with ErrCircuitOpen after failureThreshold consecutive failures
+// After the cooldown a single trial request is allowed, which closes the circuit if it succeeds.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) ClientOption {
+	return func(client *clientDefaults) {
+		client.breakerThreshold = failureThreshold
+		client.breakerCooldown = cooldown
+	}
+}
+
+// WithStrictDecoding fails responses that contain fields the client does not recognize
+func WithStrictDecoding(strictDecoding bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.strictDecoding = strictDecoding
+	}
+}
+
+// WithMaxResponseBytes overrides the maximum size of a response body, which defaults to 1 MiB
+// A non-positive value removes the limit.
+func WithMaxResponseBytes(maxResponseBytes int64) ClientOption {
+	return func(client *clientDefaults) {
+		client.maxResponseBytes = maxResponseBytes
+	}
+}
+
+// WithNormalizeNames trims and lowercases names before they are sent to the API
+func WithNormalizeNames(normalizeNames bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.normalizeNames = normalizeNames
+	}
+}
+
+// WithRestoreNames sets Prediction.Name back to the trimmed input name rather than the name the API returned
+func WithRestoreNames(restoreNames bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.restoreNames = restoreNames
+	}
+}
+
+// WithChunkTimeout sets a deadline for each chunk requested by BatchPredictPartial
+// The default is 30 seconds. A zero duration means no timeout.
+func WithChunkTimeout(chunkTimeout time.Duration) ClientOption {
+	return func(client *clientDefaults) {
+		client.chunkTimeout = chunkTimeout
+	}
+}
+
+// WithMetrics records the duration, status code, and error of each request
+func WithMetrics(metrics MetricsRecorder) ClientOption {
+	return func(client *clientDefaults) {
+		client.metrics = metrics
+	}
+}
+
+// WithClock overrides the clock used for time-dependent behavior such as retry backoff and cache expiry
+func WithClock(clock Clock) ClientOption {
+	return func(client *clientDefaults) {
+		client.clock = clock
+	}
+}
+
+// WithCoalescing shares a single request between concurrent predictions for the same name and country
+// Callers waiting on a shared request receive its result, including any error from the context of the first caller.
+func WithCoalescing(coalescing bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.coalescing = coalescing
+	}
+}
+
+// WithRedirectPolicy overrides how redirects are followed, in the same way as http.Client.CheckRedirect
+// The API key is always removed from redirects to another host before the policy is called.
+func WithRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) ClientOption {
+	return func(client *clientDefaults) {
+		client.redirectPolicy = policy
+	}
+}
+
+// WithNoRedirects fails requests that are redirected with ErrRedirectsDisabled
+func WithNoRedirects() ClientOption {
+	return WithRedirectPolicy(func(req *http.Request, via []*http.Request) error {
+		return ErrRedirectsDisabled
+	})
+}
+
+// WithParamNames overrides the query parameter names for agify-compatible endpoints that do not use the agify.io names
+// The API key parameter applies to every service, while the other parameters only apply to agify requests.
+func WithParamNames(params ParamConfig) ClientOption {
+	return func(client *clientDefaults) {
+		client.params = params
+	}
+}
+
+// WithLogger logs requests at debug level and retries and failures at info and warn levels
+// Nothing is logged without a logger, and the context of each call is passed to the logger's handler.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(client *clientDefaults) {
+		client.logger = logger
+	}
+}
+
+// WithJitterSource overrides the source of randomness for the jitter added to retry backoff
+// The default source is seeded from the current time, and a fixed source makes the backoff schedule reproducible.
+func WithJitterSource(source rand.Source) ClientOption {
+	return func(client *clientDefaults) {
+		client.jitterSource = source
+	}
+}
+
+// WithFallbackURL sets a mirror of the agify API that is tried once when a request to the primary URL fails to connect
+// HTTP error responses, redirect errors, and timeouts from the primary do not trigger the fallback, and the same API key and headers are sent.
+func WithFallbackURL(fallbackUrl string) ClientOption {
+	return func(client *clientDefaults) {
+		client.fallbackUrl = fallbackUrl
+	}
+}
+
+// WithTransportTuning sets the connection pool limits of a clone of the http client's transport
+// maxIdleConns limits idle connections across all hosts, maxConnsPerHost limits connections to the API and also sets how many may stay idle,
+// and idleTimeout is how long an idle connection is kept. Zero means no limit, as with http.Transport.
+// The default transport keeps 100 idle connections, 2 idle connections per host, no limit per host, and closes idle connections after 90 seconds.
+func WithTransportTuning(maxIdleConns int, maxConnsPerHost int, idleTimeout time.Duration) ClientOption {
+	return func(client *clientDefaults) {
+		client.transportTuning = &transportTuning{
+			maxIdleConns:    maxIdleConns,
+			maxConnsPerHost: maxConnsPerHost,
+			idleTimeout:     idleTimeout,
+		}
+	}
+}
+
+// WithDefaultCountry sets the country used by requests that do not specify one
+// An explicit country always takes precedence, an empty country uses the default, and NoCountry sends no country.
+func WithDefaultCountry(country string) ClientOption {
+	return func(client *clientDefaults) {
+		client.defaultCountry = country
+	}
+}
+
+// WithValidateCountry rejects countries that are not ISO 3166-1 alpha-2 codes with ErrInvalidCountry before making a request
+// Valid codes are accepted in any case and sent in upper case.
+func WithValidateCountry(validateCountry bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.validateCountry = validateCountry
+	}
+}
+
+// WithNotFoundAsNil returns a nil prediction without an error when a prediction responds with a 404
+// Other error statuses are still returned as errors, and batch requests are unaffected.
+func WithNotFoundAsNil(notFoundAsNil bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.notFoundAsNil = notFoundAsNil
+	}
+}
+
+// WithRetryBudget limits retries across the client to a ratio of successful requests, so an outage fails fast instead of multiplying load
+// A ratio of 0.1 allows one retry for every ten successes, on top of an initial allowance of 10 retries.
+// Once the budget is spent, failed requests are returned without retrying until more requests succeed.
+func WithRetryBudget(ratio float64) ClientOption {
+	return func(client *clientDefaults) {
+		client.retryBudgetRatio = ratio
+	}
+}
+
+// WithConditionalRequests stores the ETag of each prediction and sends it as If-None-Match on the next request for the same name and country
+// A 304 Not Modified response returns the stored prediction without decoding, along with the rate limit from the 304 response.
+func WithConditionalRequests(conditionalRequests bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.conditionalRequests = conditionalRequests
+	}
+}
+
+// WithTLSConfig installs a copy of the TLS config on a clone of the http client's transport and negotiates HTTP/2 when the server supports it
+// A config with a minimum version above its maximum version is reported on the first request.
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(client *clientDefaults) {
+		client.tlsConfig = tlsConfig
+	}
+}
+
+// WithRetryPolicy replaces the built-in retry decision with a custom policy
+// The policy is called after every attempt, including successful ones, and maxRetries is not applied so the policy must stop on its own.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(client *clientDefaults) {
+		client.retryPolicy = policy
+	}
+}
+
+// WithRateLimiter throttles outgoing requests to rps requests per second with bursts of up to burst requests
+// The limiter is shared by every call on the client and waits before each HTTP request, including retries, until the context is done.
+// A burst below 1 is treated as 1.
+func WithRateLimiter(rps float64, burst int) ClientOption {
+	return func(client *clientDefaults) {
+		client.rateLimiterRps = rps
+		client.rateLimiterBurst = burst
+	}
+}
+
+// WithNDJSONErrorLines makes PredictToNDJSON write an {"name","error"} line for each name in a failed chunk and continue, instead of stopping at the first failed chunk
+func WithNDJSONErrorLines(ndjsonErrorLines bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.ndjsonErrorLines = ndjsonErrorLines
+	}
+}
+
+// WithMaxBatchConcurrency overrides the number of chunks of a single batch requested in parallel, so one large batch does not take every slot allowed by WithConcurrency
+// By default a single batch can use as many chunks as WithConcurrency allows.
+func WithMaxBatchConcurrency(maxBatchConcurrency int) ClientOption {
+	return func(client *clientDefaults) {
+		client.maxBatchConcurrency = maxBatchConcurrency
+	}
+}
+
+// WithNetwork forces the address family of connections on a clone of the http client's transport, such as "tcp4" for IPv4 or "tcp6" for IPv6
+// By default both are tried. Any other network is reported on the first request.
+func WithNetwork(network string) ClientOption {
+	return func(client *clientDefaults) {
+		client.network = network
+	}
+}
+
+// WithQuotaAwarePacing waits for the rate limit window to reset between the chunks of a sequential batch when the remaining quota reported by the previous chunk is less than the names in the next chunk
+// This avoids 429 responses on long batches, and the wait stops when the context is done.
+func WithQuotaAwarePacing(quotaAwarePacing bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.quotaAwarePacing = quotaAwarePacing
+	}
+}
+
+// WithCollectErrors keeps requesting the remaining chunks of a batch after a chunk fails
+// Every name keeps its input position in the results, and the MultiError lists the failed names along with each chunk error.
+func WithCollectErrors(collectErrors bool) ClientOption {
+	return func(client *clientDefaults) {
+		client.collectErrors = collectErrors
+	}
+}
+
+// WithUserAgentSuffix appends an identifier such as "my-app/1.2" to the default User-Agent header, keeping the library version
+// It is ignored when WithUserAgent overrides the whole header.
+func WithUserAgentSuffix(suffix string) ClientOption {
+	return func(client *clientDefaults) {
+		client.userAgentSuffix = suffix
+	}
+}
+
+// WithMaxInFlight limits the number of HTTP requests the client has in flight at once across every method and goroutine
+// A request waits for a free slot until its context is done, and slots are not held while waiting to retry.
+func WithMaxInFlight(maxInFlight int) ClientOption {
+	return func(client *clientDefaults) {
+		client.maxInFlight = maxInFlight
+	}
+}
+
 // NewClient creates a client to call agify.io
 // By default, the client will use the public API URL without an API key.
 // The default configuration can be overridden by passing in options.
 func NewClient(opts ...ClientOption) *Client {
 	// We use the default option to prevent Client options from having access to private data in the client
 	defaults := &clientDefaults{
-		apiKey:  "",
-		baseUrl: "https://api.agify.io",
-		http:    &http.Client{},
+		apiKey:           "",
+		http:             &http.Client{},
+		chunkSize:        defaultChunkSize,
+		userAgent:        defaultUserAgent,
+		concurrency:      defaultConcurrency,
+		chunkTimeout:     defaultChunkTimeout,
+		tracerProvider:   noop.NewTracerProvider(),
+		serviceUrls:      defaultServiceUrls(),
+		maxResponseBytes: defaultMaxResponseBytes,
+		metrics:          noopMetrics{},
+		clock:            realClock{},
 	}
 
 	for _, opt := range opts {
 		opt(defaults)
 	}
 
+	// A nil http client would panic on the first request, so fall back to a usable one
+	if defaults.http == nil {
+		defaults.http = &http.Client{}
+	}
+
+	if defaults.clock == nil {
+		defaults.clock = realClock{}
+	}
+
+	if defaults.userAgent == defaultUserAgent && defaults.userAgentSuffix != "" {
+		defaults.userAgent += " " + defaults.userAgentSuffix
+	}
+
+	// A single batch can use every slot allowed by WithConcurrency unless it is limited separately
+	if defaults.maxBatchConcurrency <= 0 {
+		defaults.maxBatchConcurrency = defaults.concurrency
+	}
+
+	if defaults.metrics == nil {
+		defaults.metrics = noopMetrics{}
+	}
+
+	if defaults.tracerProvider == nil {
+		defaults.tracerProvider = noop.NewTracerProvider()
+	}
+
+	defaults.params = defaults.params.withDefaults()
+	httpClient, configErr := defaults.configureHttpClient()
+
 	return &Client{
-		apiKey:  defaults.apiKey,
-		baseUrl: defaults.baseUrl,
-		http:    defaults.http,
+		http:      httpClient,
+		chunkSize: defaults.chunkSize,
+
+		maxRetries:        defaults.maxRetries,
+		retryBaseDelay:    defaults.retryBaseDelay,
+		retryServerErrors: defaults.retryServerErrors,
+		userAgent:         defaults.userAgent,
+		timeout:           defaults.timeout,
+		dedup:             defaults.dedup,
+		chunkSlots:        newSemaphore(defaults.concurrency),
+		skipEmpty:         defaults.skipEmpty,
+		headers:           defaults.headers,
+
+		cache:         newPredictionCache(defaults.cacheTTL, defaults.clock),
+		compression:   defaults.compression,
+		requestHooks:  defaults.requestHooks,
+		responseHooks: defaults.responseHooks,
+		rateLimitWait: defaults.rateLimitWait,
+		tracer:        defaults.tracerProvider.Tracer(tracerName),
+
+		configErr:           configErr,
+		breaker:             newCircuitBreaker(defaults.breakerThreshold, defaults.breakerCooldown, defaults.clock),
+		serviceUrls:         defaults.serviceUrls,
+		strictDecoding:      defaults.strictDecoding,
+		maxResponseBytes:    defaults.maxResponseBytes,
+		normalizeNames:      defaults.normalizeNames,
+		restoreNames:        defaults.restoreNames,
+		apiKeys:             newApiKeyPool(defaults.apiKeyList(), defaults.skipLimitedApiKeys, defaults.clock),
+		chunkTimeout:        defaults.chunkTimeout,
+		metrics:             defaults.metrics,
+		clock:               defaults.clock,
+		calls:               newCallGroup(defaults.coalescing),
+		retryBudget:         newRetryBudget(defaults.retryBudgetRatio),
+		limiter:             newRequestLimiter(defaults.rateLimiterRps, defaults.rateLimiterBurst, defaults.clock),
+		params:              defaults.params,
+		logger:              defaults.logger,
+		jitter:              newJitterSource(defaults.jitterSource),
+		fallbackUrl:         defaults.fallbackUrl,
+		defaultCountry:      defaults.defaultCountry,
+		validateCountry:     defaults.validateCountry,
+		notFoundAsNil:       defaults.notFoundAsNil,
+		etags:               newEtagStore(defaults.conditionalRequests),
+		retryPolicy:         defaults.retryPolicy,
+		ndjsonErrorLines:    defaults.ndjsonErrorLines,
+		maxBatchConcurrency: defaults.maxBatchConcurrency,
+		quotaAwarePacing:    defaults.quotaAwarePacing,
+		collectErrors:       defaults.collectErrors,
+		inFlight:            newSemaphore(defaults.maxInFlight),
+	}
+}
+
+// NewClientStrict creates a client to call agify.io and validates the configuration
+// Unlike NewClient, invalid options are returned as an error rather than on the first request.
+func NewClientStrict(opts ...ClientOption) (*Client, error) {
+	client := NewClient(opts...)
+
+	if err := client.validate(); err != nil {
+		return nil, err
 	}
+
+	return client, nil
 }
 
 // Predict returns the age probability for a name
 func (client *Client) Predict(name string) (*Prediction, *RateLimit, error) {
-	return client.PredictWithCountry(name, "")
+	return client.PredictWithCountryContext(context.Background(), name, "")
+}
+
+// PredictContext returns the age probability for a name using the provided context
+func (client *Client) PredictContext(ctx context.Context, name string) (*Prediction, *RateLimit, error) {
+	return client.PredictWithCountryContext(ctx, name, "")
 }
 
 // PredictWithCountry returns the age probability for a name in a country
 func (client *Client) PredictWithCountry(name string, country string) (*Prediction, *RateLimit, error) {
-	url, _ := url.Parse(client.baseUrl)
-	values := url.Query()
+	return client.PredictWithCountryContext(context.Background(), name, country)
+}
 
-	values.Add("name", name)
+// PredictWithCountryContext returns the age probability for a name in a country using the provided context
+func (client *Client) PredictWithCountryContext(ctx context.Context, name string, country string) (*Prediction, *RateLimit, error) {
+	prediction, meta, err := client.PredictWithMeta(ctx, name, country)
+	return prediction, meta.rateLimitOrNil(), err
+}
+
+// PredictWithMeta returns the age probability for a name in a country along with the response metadata
+// The prediction is nil without an error when WithNotFoundAsNil is enabled and the API responds with a 404.
+func (client *Client) PredictWithMeta(ctx context.Context, name string, country string) (*Prediction, *ResponseMeta, error) {
+	return client.predictWithOptions(ctx, name, &callOptions{country: country})
+}
+
+// predictWithOptions returns the age probability for a name using the options for a single call
+func (client *Client) predictWithOptions(ctx context.Context, name string, options *callOptions) (*Prediction, *ResponseMeta, error) {
+	if err := validateName(name); err != nil {
+		return nil, nil, err
+	}
+
+	query := client.normalizeName(name)
+	country := options.country
+
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+
+	if !options.skipCache {
+		if prediction, ok := client.cache.get(query, country); ok {
+			return client.restoreName(prediction, name), nil, nil
+		}
+	}
+
+	callKey := canonicalKey(query, country)
 
-	if country != "" {
-		values.Add("country_id", country)
+	if options.withoutApiKey {
+		ctx = context.WithValue(ctx, withoutApiKeyContextKey{}, true)
+		callKey += "\x00public"
 	}
 
-	if client.apiKey != "" {
-		values.Add("apikey", client.apiKey)
+	ctx, span := client.startSpan(ctx, "agify.Predict", 1, country)
+	prediction, meta, err := client.calls.do(ctx, callKey, func() (*Prediction, *ResponseMeta, error) {
+		return client.predict(ctx, query, country)
+	})
+	endSpan(span, err)
+
+	if client.notFoundAsNil && meta.statusCode() == http.StatusNotFound {
+		return nil, meta, nil
 	}
 
-	url.RawQuery = values.Encode()
+	if err != nil {
+		return nil, meta, err
+	}
 
-	body, rateLimit, err := client.get(url.String())
+	return client.restoreName(prediction, name), meta, nil
+}
+
+// predict makes the API request for a name in a country and caches the result
+func (client *Client) predict(ctx context.Context, name string, country string) (*Prediction, *ResponseMeta, error) {
+	prediction, meta, err := client.requestPrediction(ctx, name, country)
 
 	if err != nil {
-		return nil, rateLimit, err
+		return nil, meta, err
+	}
+
+	client.cache.set(name, country, prediction)
+
+	return prediction, meta, nil
+}
+
+// requestPrediction makes the API request for a name in a country without reading or writing the cache
+func (client *Client) requestPrediction(ctx context.Context, name string, country string) (*Prediction, *ResponseMeta, error) {
+	url, err := client.predictUrl(name, country)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := canonicalKey(name, country)
+	stored, ok := client.etags.get(key)
+
+	if ok {
+		ctx = context.WithValue(ctx, ifNoneMatchContextKey{}, stored.etag)
+	}
+
+	body, meta, err := client.get(ctx, url)
+
+	if err != nil {
+		return nil, meta, err
 	}
 
 	var prediction Prediction
-	err = json.Unmarshal(body, &prediction)
+
+	if ok && meta.StatusCode == http.StatusNotModified {
+		prediction = stored.prediction
+	} else {
+		err = client.decode(body, meta.contentType(), &prediction)
+
+		if err != nil {
+			return nil, meta, err
+		}
+
+		client.etags.set(key, meta.response.Header.Get("ETag"), &prediction)
+	}
+
+	return &prediction, meta, nil
+}
+
+// parseBaseUrl parses a configured base URL
+func parseBaseUrl(rawUrl string) (*url.URL, error) {
+	baseUrl, err := url.Parse(rawUrl)
 
 	if err != nil {
-		return nil, rateLimit, err
+		return nil, fmt.Errorf("agify: invalid base url: %w", err)
 	}
 
-	return &prediction, rateLimit, nil
+	return baseUrl, nil
 }
 
-// BatchPredict returns the age probability for a list of names
-func (client *Client) BatchPredict(names []string) ([]Prediction, *RateLimit, error) {
-	return client.BatchPredictWithCountry(names, "")
+// Do sends the request with the client's API key, headers, timeout, retries, rate limiting and logging, and returns the response body
+// It lets requests to other endpoints reuse the client. A request with a body must set GetBody so it can be retried.
+// A status other than 200 is returned as an APIError, along with the rate limit from the response.
+func (client *Client) Do(ctx context.Context, req *http.Request) ([]byte, *RateLimit, error) {
+	body, meta, err := client.doRequest(ctx, req)
+	return body, meta.rateLimitOrNil(), err
 }
 
-// BatchPredict returns the age probability for a list of names in a country
-func (client *Client) BatchPredictWithCountry(names []string, country string) ([]Prediction, *RateLimit, error) {
-	url, _ := url.Parse(client.baseUrl)
-	values := url.Query()
+// get makes a GET request to the URL with doRequest
+func (client *Client) get(ctx context.Context, url string) ([]byte, *ResponseMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client.doRequest(ctx, req)
+}
 
-	values.Add("country_id", country)
+// doRequest makes the API request, retrying if configured, and returns the response body
+// Every request from the client goes through here. If the request could not connect it is tried once more against the fallback URL.
+func (client *Client) doRequest(ctx context.Context, req *http.Request) ([]byte, *ResponseMeta, error) {
+	body, meta, err := client.doWithRetry(ctx, req)
 
-	for _, name := range names {
-		values.Add("name[]", name)
+	if !isConnectError(err) || ctx.Err() != nil {
+		return body, meta, err
 	}
 
-	if client.apiKey != "" {
-		values.Add("apikey", client.apiKey)
+	fallback, ok := client.fallbackFor(req.URL.String())
+
+	if !ok {
+		return body, meta, err
 	}
 
-	url.RawQuery = values.Encode()
-	body, rateLimit, err := client.get(url.String())
+	fallbackReq := req.Clone(ctx)
+	fallbackReq.URL, _ = url.Parse(fallback)
+
+	client.logRetry(ctx, fallback, 0, 0, err)
+
+	start := client.clock.Now()
+	body, meta, err = client.send(ctx, fallbackReq)
+	client.metrics.ObserveRequest(client.clock.Now().Sub(start), meta.statusCode(), err)
 
 	if err != nil {
-		return nil, rateLimit, err
+		client.logFailure(ctx, fallback, 0, err)
 	}
 
-	var predictions []Prediction
-	err = json.Unmarshal(body, &predictions)
+	return body, meta, err
+}
 
-	if err != nil {
-		return nil, rateLimit, err
+// doWithRetry makes the API request against a single URL, retrying if configured
+func (client *Client) doWithRetry(ctx context.Context, req *http.Request) ([]byte, *ResponseMeta, error) {
+	if client.configErr != nil {
+		return nil, nil, client.configErr
 	}
 
-	return predictions, rateLimit, nil
+	url := req.URL.String()
+	waited := false
+
+	for attempt := 0; ; {
+		if err := client.breaker.allow(); err != nil {
+			return nil, nil, err
+		}
+
+		start := client.clock.Now()
+		body, meta, err := client.send(ctx, req)
+
+		// Errors from before the request was sent, such as waiting for a rate limiter token, say nothing about the API
+		if meta != nil || isTransportError(err) {
+			client.breaker.record(err)
+		} else {
+			client.breaker.abandon()
+		}
+
+		client.metrics.ObserveRequest(client.clock.Now().Sub(start), meta.statusCode(), err)
+
+		// Waiting for the rate limit window is separate from the retry attempts and only happens once
+		if client.rateLimitWait && !waited && hasStatusCode(err, http.StatusTooManyRequests) {
+			if wait := meta.rateLimitOrNil().waitDuration(); wait > 0 {
+				waited = true
+				client.logRetry(ctx, url, attempt, wait, err)
+
+				if err := client.clock.Sleep(ctx, wait); err != nil {
+					return nil, meta, err
+				}
+
+				continue
+			}
+		}
+
+		if err == nil {
+			client.retryBudget.deposit()
+		}
+
+		retry, delay := client.retryDecision(meta, err, attempt)
+
+		if !retry || !client.retryBudget.withdraw() {
+			if err != nil {
+				client.logFailure(ctx, url, attempt, err)
+			}
+
+			return body, meta, err
+		}
+
+		client.logRetry(ctx, url, attempt, delay, err)
+
+		if err := client.clock.Sleep(ctx, delay); err != nil {
+			return nil, meta, err
+		}
+
+		attempt++
+	}
 }
 
-// get makes the API request and returns the response body
-func (client *Client) get(url string) ([]byte, *RateLimit, error) {
-	resp, err := http.Get(url)
+// send makes a single attempt of the API request on a copy of it and returns the response body
+// A cancelled context is returned before the request is made, and wrapped by the transport error otherwise
+func (client *Client) send(ctx context.Context, template *http.Request) ([]byte, *ResponseMeta, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := client.limiter.wait(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	if err := client.inFlight.acquire(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	defer client.inFlight.release()
+
+	if client.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.timeout)
+		defer cancel()
+	}
+
+	req, err := cloneRequest(ctx, template)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	rateLimit := &RateLimit{
-		Limit:     resp.Header.Get("X-Rate-Limit-Limit"),
-		Remaining: resp.Header.Get("X-Rate-Limit-Remaining"),
-		Reset:     resp.Header.Get("X-Rate-Reset"),
+	var apiKey string
+
+	if !isWithoutApiKey(ctx) {
+		apiKey = client.apiKeys.next()
+	}
+
+	if apiKey != "" {
+		values := req.URL.Query()
+		values.Set(client.params.ApiKey, apiKey)
+		req.URL.RawQuery = values.Encode()
+	}
+
+	url := req.URL.String()
+
+	for key, values := range client.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", client.userAgent)
+	}
+
+	ifNoneMatch, conditional := ctx.Value(ifNoneMatchContextKey{}).(string)
+
+	if conditional {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+
+	// Setting Accept-Encoding disables the transport's transparent decompression, so readBody handles it instead
+	if client.compression {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	for _, hook := range client.requestHooks {
+		hook(req)
 	}
 
+	client.logRequest(ctx, req.Method, url)
+
+	start := client.clock.Now()
+	resp, err := client.http.Do(req)
+
+	if err != nil {
+		return nil, nil, wrapTransportError(req.Method, url, client.params.ApiKey, err)
+	}
+
+	setSpanStatusCode(ctx, resp.StatusCode)
+
 	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp, client.maxResponseBytes)
+
+	receivedAt := client.clock.Now()
+	meta := &ResponseMeta{
+		RateLimit:  parseRateLimit(resp.Header, receivedAt),
+		Latency:    receivedAt.Sub(start),
+		StatusCode: resp.StatusCode,
+		response:   resp,
+		body:       body,
+		receivedAt: receivedAt,
+	}
+
+	client.logResponse(ctx, req.Method, url, meta)
+
+	// Each hook gets a fresh reader over the bytes already read, and the body is restored afterwards for anything that uses the response later
+	for _, hook := range client.responseHooks {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		hook(resp, meta.Latency)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		client.apiKeys.markLimited(apiKey, meta.RateLimit.waitDuration())
+	}
+
+	if resp.StatusCode != http.StatusOK && !(conditional && resp.StatusCode == http.StatusNotModified) {
+		return nil, meta, newAPIError(resp.StatusCode, body)
+	}
+
+	if err != nil {
+		return nil, meta, err
+	}
+
+	return body, meta, nil
+}
+
+// cloneRequest copies the request for an attempt with the context, replaying the body with GetBody
+func cloneRequest(ctx context.Context, template *http.Request) (*http.Request, error) {
+	req := template.Clone(ctx)
+
+	if template.Body == nil || template.Body == http.NoBody {
+		return req, nil
+	}
+
+	if template.GetBody == nil {
+		return nil, errors.New("agify: request body cannot be retried without GetBody")
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		var resp errorResponse
-		err = json.Unmarshal(body, &resp)
+	body, err := template.GetBody()
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = body
+
+	return req, nil
+}
+
+// readBody reads the response body, decompressing it if the server gzipped it
+// The limit applies to the decompressed body and a non-positive limit reads the whole body.
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	var reader io.Reader = resp.Body
+
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gzipReader, err := gzip.NewReader(resp.Body)
 
 		if err != nil {
-			return nil, rateLimit, err
+			return nil, err
 		}
 
-		return nil, rateLimit, errors.New(resp.Error)
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	if limit <= 0 {
+		return io.ReadAll(reader)
 	}
 
+	// Reading one byte past the limit detects bodies that exceed it
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+
 	if err != nil {
-		return nil, rateLimit, err
+		return nil, err
+	}
+
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, limit)
+	}
+
+	return body, nil
+}
+
+// rateLimitOrNil returns the rate limit from the metadata, or nil if there is no metadata
+func (meta *ResponseMeta) rateLimitOrNil() *RateLimit {
+	if meta == nil {
+		return nil
+	}
+
+	return meta.RateLimit
+}
+
+// statusCode returns the status code from the metadata, or zero if there is no metadata
+func (meta *ResponseMeta) statusCode() int {
+	if meta == nil {
+		return 0
+	}
+
+	return meta.StatusCode
+}
+
+// contentType returns the Content-Type of the response, or an empty string if there is no response
+func (meta *ResponseMeta) contentType() string {
+	if meta == nil || meta.response == nil {
+		return ""
 	}
 
-	return body, rateLimit, nil
+	return meta.response.Header.Get("Content-Type")
 }
@@ -1,9 +1,14 @@
 package agify
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -32,13 +37,14 @@ func TestShouldGetPredictionForName(t *testing.T) {
 	assert.Equal(t, "michael", result.Name)
 	assert.Equal(t, "US", result.Country)
 
-	assert.Equal(t, "1000", rateLimit.Limit)
-	assert.Equal(t, "728", rateLimit.Remaining)
-	assert.Equal(t, "15281", rateLimit.Reset)
+	assert.Equal(t, 1000, rateLimit.Limit)
+	assert.Equal(t, 728, rateLimit.Remaining)
+	assert.Equal(t, 15281*time.Second, rateLimit.Reset)
 }
 
 func TestShouldGetErrorWhenUnauthorized(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
 		w.WriteHeader(http.StatusUnauthorized)
 		w.Write([]byte(`{ "error": "Invalid API key" }`))
 	}))
@@ -54,6 +60,7 @@ func TestShouldGetErrorWhenUnauthorized(t *testing.T) {
 
 func TestShouldGetErrorWhenTooManyRequests(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
 		w.WriteHeader(http.StatusTooManyRequests)
 		w.Write([]byte(`{ "error": "Request limit reached" }`))
 	}))
@@ -69,6 +76,7 @@ func TestShouldGetErrorWhenTooManyRequests(t *testing.T) {
 
 func TestShouldGetErrorWhenUnprocessable(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		w.Write([]byte(`{ "error": "Too many requests" }`))
 	}))
@@ -116,3 +124,441 @@ func TestShouldHandleBatchPrediction(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Len(t, result, 3)
 }
+
+// roundTripperFunc allows a function to be used as an http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestShouldUseConfiguredHttpClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	called := false
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			called = true
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	}
+
+	client := NewClient(WithUrl(server.URL), WithClient(httpClient))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.True(t, called)
+}
+
+func TestShouldDefaultNilHttpClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithClient(nil))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+}
+
+func TestShouldCancelPredictionMidFlight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.PredictContext(ctx, "michael")
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestShouldCancelBatchPredictionMidFlight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.BatchPredictContext(ctx, []string{"michael", "matthew"})
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestShouldReturnEarlyWhenContextAlreadyCancelled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := NewClient(WithUrl(server.URL))
+	_, rateLimit, err := client.PredictContext(ctx, "michael")
+
+	assert.Nil(t, rateLimit)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 0, requests)
+}
+
+func TestShouldSendDefaultUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "agify-go/"+Version, r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	_, _, err := client.Predict("michael")
+	assert.Nil(t, err)
+}
+
+func TestShouldSendConfiguredUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "my-app/1.2", r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithUserAgent("my-app/1.2"))
+	_, _, err := client.BatchPredict([]string{"michael"})
+	assert.Nil(t, err)
+}
+
+func TestShouldAppendUserAgentSuffix(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithUserAgentSuffix("my-app/1.2"))
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "agify-go/"+Version+" my-app/1.2", userAgent)
+
+	for _, opts := range [][]ClientOption{
+		{WithUserAgent("other/2.0"), WithUserAgentSuffix("my-app/1.2")},
+		{WithUserAgentSuffix("my-app/1.2"), WithUserAgent("other/2.0")},
+	} {
+		client = NewClient(append([]ClientOption{WithUrl(server.URL)}, opts...)...)
+		_, _, err = client.Predict("michael")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "other/2.0", userAgent)
+	}
+}
+
+func TestShouldReturnResponseLatency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("X-Rate-Limit-Remaining", "728")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, meta, err := client.PredictWithMeta(context.Background(), "michael", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.GreaterOrEqual(t, meta.Latency, 20*time.Millisecond)
+	assert.Equal(t, 728, meta.RateLimit.Remaining)
+}
+
+func TestShouldTimeoutSlowRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithTimeout(10*time.Millisecond))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestShouldPreferSoonerContextDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	client := NewClient(WithUrl(server.URL), WithTimeout(time.Minute))
+	_, _, err := client.PredictContext(ctx, "michael")
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestShouldReturnErrorForInvalidBaseUrl(t *testing.T) {
+	client := NewClient(WithUrl("://bad"))
+
+	result, rateLimit, err := client.Predict("michael")
+	assert.Nil(t, result)
+	assert.Nil(t, rateLimit)
+	assert.ErrorContains(t, err, "invalid base url")
+
+	results, _, err := client.BatchPredict([]string{"michael"})
+	assert.Nil(t, results)
+	assert.ErrorContains(t, err, "invalid base url")
+}
+
+func TestShouldSendCustomHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Corp-Token"))
+		assert.Equal(t, []string{"a", "b"}, r.Header.Values("X-Trace"))
+		assert.Equal(t, "my-app/1.2", r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithUrl(server.URL),
+		WithHeader("X-Corp-Token", "secret"),
+		WithHeader("X-Trace", "a"),
+		WithHeader("X-Trace", "b"),
+		WithUserAgent("my-app/1.2"),
+	)
+
+	_, _, err := client.Predict("michael")
+	assert.Nil(t, err)
+}
+
+func TestShouldCallRequestAndResponseHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+
+	client := NewClient(
+		WithUrl(server.URL),
+		WithRequestHook(func(r *http.Request) {
+			assert.Equal(t, "michael", r.URL.Query().Get("name"))
+			calls = append(calls, "request 1")
+		}),
+		WithRequestHook(func(r *http.Request) {
+			calls = append(calls, "request 2")
+		}),
+		WithResponseHook(func(r *http.Response, latency time.Duration) {
+			assert.Equal(t, http.StatusOK, r.StatusCode)
+			assert.GreaterOrEqual(t, latency, 5*time.Millisecond)
+			calls = append(calls, "response")
+		}),
+	)
+
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"request 1", "request 2", "response"}, calls)
+}
+
+func TestShouldLetResponseHooksReadBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	var bodies []string
+	readBody := func(r *http.Response, latency time.Duration) {
+		body, err := io.ReadAll(r.Body)
+		assert.Nil(t, err)
+		bodies = append(bodies, string(body))
+	}
+
+	client := NewClient(WithUrl(server.URL), WithResponseHook(readBody), WithResponseHook(readBody))
+	_, resp, err := client.PredictRaw(context.Background(), "michael")
+	assert.Nil(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	expected := `{"name":"michael","age":70,"count":875}`
+	assert.Equal(t, []string{expected, expected}, bodies)
+	assert.Equal(t, expected, string(body))
+}
+
+func TestShouldRejectOversizedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875,"padding":"`))
+		w.Write(make([]byte, 2048))
+		w.Write([]byte(`"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithMaxResponseBytes(1024))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, ErrResponseTooLarge))
+}
+
+func TestShouldAcceptResponseWithinLimit(t *testing.T) {
+	body := `{"name":"michael","age":70,"count":875}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithMaxResponseBytes(int64(len(body))))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+}
+
+func TestShouldReturnNilRateLimitWithoutHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, rateLimit, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Nil(t, rateLimit)
+}
+
+// flakyEndpointHandler rate limits the first request to each path, recording the API key and header of every request
+func flakyEndpointHandler(t *testing.T, requests map[string]int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		assert.Equal(t, "key", r.URL.Query().Get("apikey"))
+		assert.Equal(t, "value", r.Header.Get("X-Test"))
+		assert.Equal(t, "agify-go/"+Version, r.Header.Get("User-Agent"))
+
+		if requests[r.URL.Path] == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{ "error": "Request limit reached" }`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/nationalize":
+			w.Write([]byte(`{"name":"michael","country":[{"country_id":"US","probability":0.1}]}`))
+		case "/genderize":
+			w.Write([]byte(`{"name":"michael","gender":"male","probability":0.99,"count":100}`))
+		default:
+			w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+		}
+	}
+}
+
+func TestShouldApplySharedRequestPathToEveryEndpoint(t *testing.T) {
+	requests := make(map[string]int)
+	server := httptest.NewServer(flakyEndpointHandler(t, requests))
+	defer server.Close()
+
+	client := NewClient(
+		WithUrl(server.URL+"/agify"),
+		WithGenderizeUrl(server.URL+"/genderize"),
+		WithNationalizeUrl(server.URL+"/nationalize"),
+		WithApiKey("key"),
+		WithHeader("X-Test", "value"),
+		WithRetry(1, time.Millisecond),
+	)
+
+	_, _, err := client.Predict("michael")
+	assert.Nil(t, err)
+
+	_, _, err = client.Genderize("michael")
+	assert.Nil(t, err)
+
+	_, _, err = client.Nationalize("michael")
+	assert.Nil(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/custom?name=michael", nil)
+	body, _, err := client.Do(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), "michael")
+
+	assert.Equal(t, map[string]int{"/agify": 2, "/genderize": 2, "/nationalize": 2, "/custom": 2}, requests)
+}
+
+func TestShouldReplayRequestBodyOnRetry(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("X-Rate-Limit-Remaining", "10")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithRetry(1, time.Millisecond))
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("names"))
+	_, rateLimit, err := client.Do(context.Background(), req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 10, rateLimit.Remaining)
+	assert.Equal(t, []string{"names", "names"}, bodies)
+}
+
+func TestShouldReturnAPIErrorFromDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	_, _, err := client.Do(context.Background(), req)
+
+	assert.True(t, hasStatusCode(err, http.StatusNotFound))
+}
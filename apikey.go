@@ -0,0 +1,112 @@
+package agify
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// apiKeyPool rotates through API keys round-robin, optionally skipping keys that were rate limited
+type apiKeyPool struct {
+	keys        []string
+	skipLimited bool
+	clock       Clock
+
+	mu           sync.Mutex
+	index        int
+	limitedUntil map[string]time.Time
+}
+
+// apiKeyList returns the configured API keys
+func (defaults *clientDefaults) apiKeyList() []string {
+	if defaults.apiKey != "" {
+		return []string{defaults.apiKey}
+	}
+
+	return defaults.apiKeys
+}
+
+// newApiKeyPool creates a pool of API keys, or returns nil if there are no keys
+func newApiKeyPool(keys []string, skipLimited bool, clock Clock) *apiKeyPool {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return &apiKeyPool{
+		keys:         keys,
+		skipLimited:  skipLimited,
+		clock:        clock,
+		limitedUntil: make(map[string]time.Time),
+	}
+}
+
+// next returns the next API key, or an empty string if there are no keys
+// Rate limited keys are skipped when enabled, unless every key is rate limited.
+func (pool *apiKeyPool) next() string {
+	if pool == nil {
+		return ""
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	index := pool.nextIndex()
+	pool.index = (index + 1) % len(pool.keys)
+
+	return pool.keys[index]
+}
+
+// peek returns the API key the next request would use without advancing the rotation
+func (pool *apiKeyPool) peek() string {
+	if pool == nil {
+		return ""
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.keys[pool.nextIndex()]
+}
+
+// nextIndex returns the index of the key the next request would use, skipping rate limited keys when enabled
+// If every key is rate limited, the key at the current position is used. The caller must hold the lock.
+func (pool *apiKeyPool) nextIndex() int {
+	now := pool.clock.Now()
+
+	for i := 0; i < len(pool.keys); i++ {
+		index := (pool.index + i) % len(pool.keys)
+
+		if !pool.skipLimited || !now.Before(pool.limitedUntil[pool.keys[index]]) {
+			return index
+		}
+	}
+
+	return pool.index
+}
+
+// markLimited records that the key is rate limited for the duration
+func (pool *apiKeyPool) markLimited(key string, wait time.Duration) {
+	if pool == nil || !pool.skipLimited || key == "" || wait <= 0 {
+		return
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.limitedUntil[key] = pool.clock.Now().Add(wait)
+}
+
+// withApiKey sets the API key query parameter on the URL
+func withApiKey(rawUrl string, param string, apiKey string) string {
+	parsed, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return rawUrl
+	}
+
+	values := parsed.Query()
+	values.Set(param, apiKey)
+	parsed.RawQuery = values.Encode()
+
+	return parsed.String()
+}
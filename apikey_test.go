@@ -0,0 +1,113 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldRotateApiKeys(t *testing.T) {
+	var mu sync.Mutex
+	used := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		used[r.URL.Query().Get("apikey")]++
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithApiKeys("a", "b", "c"))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			client.Predict("michael")
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"a": 3, "b": 3, "c": 3}, used)
+}
+
+func TestShouldSkipRateLimitedApiKey(t *testing.T) {
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("apikey")
+		used = append(used, key)
+
+		if key == "a" {
+			w.Header().Set("X-Rate-Reset", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{ "error": "Request limit reached" }`))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithApiKeys("a", "b"), WithSkipLimitedApiKeys(true))
+
+	for i := 0; i < 4; i++ {
+		client.Predict("michael")
+	}
+
+	assert.Equal(t, []string{"a", "b", "b", "b"}, used)
+}
+
+func TestShouldPreferLastApiKeyOption(t *testing.T) {
+	client := NewClient(WithApiKeys("a", "b"), WithApiKey("c"))
+	assert.Equal(t, []string{"c"}, client.apiKeys.keys)
+
+	client = NewClient(WithApiKey("c"), WithApiKeys("a", "b"))
+	assert.Equal(t, []string{"a", "b"}, client.apiKeys.keys)
+
+	client = NewClient()
+	assert.Nil(t, client.apiKeys)
+}
+
+func TestShouldReadApiKeyFromEnv(t *testing.T) {
+	t.Setenv("AGIFY_API_KEY", "from-env")
+
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.URL.Query().Get("apikey")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithApiKeyFromEnv(""))
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "from-env", apiKey)
+}
+
+func TestShouldApplyApiKeyFromEnvInOptionOrder(t *testing.T) {
+	t.Setenv("CUSTOM_AGIFY_KEY", "from-env")
+
+	client := NewClient(WithApiKey("explicit"), WithApiKeyFromEnv("CUSTOM_AGIFY_KEY"))
+	assert.Equal(t, []string{"from-env"}, client.apiKeys.keys)
+
+	client = NewClient(WithApiKeyFromEnv("CUSTOM_AGIFY_KEY"), WithApiKey("explicit"))
+	assert.Equal(t, []string{"explicit"}, client.apiKeys.keys)
+
+	client = NewClient(WithApiKey("explicit"), WithApiKeyFromEnv("UNSET_AGIFY_KEY"))
+	assert.Equal(t, []string{"explicit"}, client.apiKeys.keys)
+
+	client = NewClient(WithApiKeyFromEnv("UNSET_AGIFY_KEY"))
+	assert.Nil(t, client.apiKeys)
+}
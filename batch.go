@@ -0,0 +1,415 @@
+package agify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultChunkSize is the maximum number of names agify.io accepts in a single batch request
+	defaultChunkSize = 10
+
+	// defaultConcurrency is the number of chunks the client requests in parallel across every batch
+	defaultConcurrency = 4
+
+	// defaultChunkTimeout is the deadline for each chunk requested by BatchPredictPartial, so a stalled chunk is reported as failed
+	defaultChunkTimeout = 30 * time.Second
+)
+
+// BatchPredict returns the age probability for a list of names
+func (client *Client) BatchPredict(names []string) ([]Prediction, *RateLimit, error) {
+	return client.BatchPredictWithCountryContext(context.Background(), names, "")
+}
+
+// BatchPredictContext returns the age probability for a list of names using the provided context
+func (client *Client) BatchPredictContext(ctx context.Context, names []string) ([]Prediction, *RateLimit, error) {
+	return client.BatchPredictWithCountryContext(ctx, names, "")
+}
+
+// BatchPredict returns the age probability for a list of names in a country
+func (client *Client) BatchPredictWithCountry(names []string, country string) ([]Prediction, *RateLimit, error) {
+	return client.BatchPredictWithCountryContext(context.Background(), names, country)
+}
+
+// BatchPredictWithCountryContext returns the age probability for a list of names in a country using the provided context
+// The names are split into chunks that are requested sequentially and the results are concatenated.
+// The returned rate limit has the lowest remaining count and farthest reset seen across the chunks.
+// If the context is done before every chunk completes, the completed predictions are returned with an IncompleteBatchError.
+// When errors are collected, every name has a prediction in its input position and the MultiError lists the names that failed,
+// whose predictions only have the name set.
+func (client *Client) BatchPredictWithCountryContext(ctx context.Context, names []string, country string) ([]Prediction, *RateLimit, error) {
+	names, err := client.validateNames(names)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queries := client.normalizeNameList(names)
+	requested, indexes := queries, make([]int, len(queries))
+
+	if client.dedup {
+		requested, indexes = dedupNames(queries)
+	} else {
+		for i := range indexes {
+			indexes[i] = i
+		}
+	}
+
+	predictions, nameErrs, rateLimit, err := client.batchPredictChunks(ctx, requested, country)
+	var multiErr *MultiError
+
+	switch {
+	case err == nil:
+		predictions = fanOutPredictions(predictions, queries, indexes)
+		return client.restoreNameList(predictions, names), rateLimit, nil
+	case ctx.Err() != nil:
+		completed, failed := client.completedPredictions(predictions, nameErrs, names, indexes, ctx.Err())
+		return completed, rateLimit, &IncompleteBatchError{Failed: failed, Err: ctx.Err()}
+	case client.collectErrors && errors.As(err, &multiErr):
+		_, multiErr.Failed = client.completedPredictions(predictions, nameErrs, names, indexes, nil)
+		predictions = fanOutPredictions(predictions, queries, indexes)
+		return client.restoreNameList(predictions, names), rateLimit, multiErr
+	default:
+		return nil, rateLimit, err
+	}
+}
+
+// completedPredictions splits the names into the predictions that were returned and the names that failed
+// The index of each name is its position in predictions, which only holds the names requested before the batch stopped.
+// A name from a failed chunk has the error from its chunk, while a name that was never requested has err.
+func (client *Client) completedPredictions(predictions []Prediction, nameErrs []error, names []string, indexes []int, err error) ([]Prediction, []FailedName) {
+	var completed []Prediction
+	var failed []FailedName
+
+	for i, index := range indexes {
+		if index >= len(predictions) {
+			failed = append(failed, FailedName{Name: names[i], Err: err})
+			continue
+		}
+
+		if nameErrs[index] != nil {
+			failed = append(failed, FailedName{Name: names[i], Err: nameErrs[index]})
+			continue
+		}
+
+		prediction := predictions[index]
+		completed = append(completed, *client.restoreName(&prediction, names[i]))
+	}
+
+	return completed, failed
+}
+
+// batchPredictChunks requests each chunk of names sequentially and concatenates the results, along with the error for each name that was not predicted
+// The first failed chunk stops the batch and the predictions before it are returned with its error.
+// When errors are collected, failed chunks get predictions with only the name set and their errors are returned together in a MultiError.
+func (client *Client) batchPredictChunks(ctx context.Context, names []string, country string) ([]Prediction, []error, *RateLimit, error) {
+	var predictions []Prediction
+	var nameErrs []error
+	var rateLimit, chunkRateLimit *RateLimit
+	var errs []error
+
+	for _, chunk := range chunkNames(names, client.chunkSize) {
+		var chunkPredictions []Prediction
+		err := client.paceQuota(ctx, chunkRateLimit, len(chunk))
+
+		if err == nil {
+			chunkPredictions, chunkRateLimit, err = client.batchPredict(ctx, chunk, country)
+			rateLimit = mergeRateLimits(rateLimit, chunkRateLimit)
+		}
+
+		if err != nil && (!client.collectErrors || ctx.Err() != nil) {
+			return predictions, nameErrs, rateLimit, err
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+			chunkPredictions = placeholderPredictions(chunk)
+		}
+
+		predictions = append(predictions, chunkPredictions...)
+
+		for range chunk {
+			nameErrs = append(nameErrs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return predictions, nameErrs, rateLimit, &MultiError{errs: errs}
+	}
+
+	return predictions, nameErrs, rateLimit, nil
+}
+
+// ConcurrentBatchPredict returns the age probability for a list of names, requesting chunks in parallel
+// The results preserve the order of the input names and any errors are joined together.
+// If a chunk is rate limited the remaining chunks are cancelled, and when errors are collected their names are listed as failed in the MultiError.
+// If the context is done before every chunk completes, the completed predictions are returned with an IncompleteBatchError.
+// The returned rate limit has the lowest remaining count and farthest reset seen across the chunks.
+func (client *Client) ConcurrentBatchPredict(ctx context.Context, names []string) ([]Prediction, *RateLimit, error) {
+	names, err := client.validateNames(names)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chunks := chunkNames(client.normalizeNameList(names), client.chunkSize)
+	results := make([][]Prediction, len(chunks))
+	rateLimits := make([]*RateLimit, len(chunks))
+	errs := make([]error, len(chunks))
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := client.batchWorkers()
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				results[i], rateLimits[i], errs[i] = client.batchPredictSlot(workerCtx, chunks[i], "")
+
+				if hasStatusCode(errs[i], http.StatusTooManyRequests) {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	for i := range chunks {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	var rateLimit *RateLimit
+
+	for _, chunkRateLimit := range rateLimits {
+		rateLimit = mergeRateLimits(rateLimit, chunkRateLimit)
+	}
+
+	var chunkErrs []error
+
+	for _, err := range errs {
+		// Chunks cancelled because another chunk was rate limited are not worth reporting
+		if err != nil && (ctx.Err() != nil || !errors.Is(err, context.Canceled)) {
+			chunkErrs = append(chunkErrs, err)
+		}
+	}
+
+	if len(chunkErrs) > 0 && ctx.Err() != nil {
+		completed, failed := client.completedChunks(results, errs, names)
+		return completed, rateLimit, &IncompleteBatchError{Failed: failed, Err: ctx.Err()}
+	}
+
+	if len(chunkErrs) > 0 && client.collectErrors {
+		_, failed := client.completedChunks(results, errs, names)
+
+		for i, err := range errs {
+			if err != nil {
+				results[i] = placeholderPredictions(chunks[i])
+			}
+		}
+
+		var predictions []Prediction
+
+		for _, chunkPredictions := range results {
+			predictions = append(predictions, chunkPredictions...)
+		}
+
+		return client.restoreNameList(predictions, names), rateLimit, &MultiError{errs: chunkErrs, Failed: failed}
+	}
+
+	if len(chunkErrs) > 0 {
+		return nil, rateLimit, joinErrors(chunkErrs)
+	}
+
+	var predictions []Prediction
+
+	for _, chunkPredictions := range results {
+		predictions = append(predictions, chunkPredictions...)
+	}
+
+	return client.restoreNameList(predictions, names), rateLimit, nil
+}
+
+// batchWorkers returns the number of chunks of a single batch to request in parallel
+func (client *Client) batchWorkers() int {
+	if client.maxBatchConcurrency <= 0 {
+		return defaultConcurrency
+	}
+
+	return client.maxBatchConcurrency
+}
+
+// batchPredictSlot makes a batch request once a slot is free in the client-wide chunk limit
+func (client *Client) batchPredictSlot(ctx context.Context, names []string, country string) ([]Prediction, *RateLimit, error) {
+	if err := client.chunkSlots.acquire(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	defer client.chunkSlots.release()
+
+	return client.batchPredict(ctx, names, country)
+}
+
+// completedChunks splits the names into the predictions from chunks that succeeded and the names from chunks that failed with the error from their chunk
+func (client *Client) completedChunks(results [][]Prediction, errs []error, names []string) ([]Prediction, []FailedName) {
+	var completed []Prediction
+	var failed []FailedName
+
+	for i, chunk := range chunkNames(names, client.chunkSize) {
+		if errs[i] != nil {
+			for _, name := range chunk {
+				failed = append(failed, FailedName{Name: name, Err: errs[i]})
+			}
+
+			continue
+		}
+
+		completed = append(completed, client.restoreNameList(results[i], chunk)...)
+	}
+
+	return completed, failed
+}
+
+// batchPredict makes a single traced batch request for a list of names in a country
+func (client *Client) batchPredict(ctx context.Context, names []string, country string) ([]Prediction, *RateLimit, error) {
+	ctx, span := client.startSpan(ctx, "agify.BatchPredict", len(names), country)
+	predictions, rateLimit, err := client.batchRequest(ctx, names, country)
+	endSpan(span, err)
+
+	return predictions, rateLimit, err
+}
+
+// batchRequest makes the API request for a list of names in a country
+func (client *Client) batchRequest(ctx context.Context, names []string, country string) ([]Prediction, *RateLimit, error) {
+	url, err := client.batchUrl(names, country)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, meta, err := client.get(ctx, url)
+	rateLimit := meta.rateLimitOrNil()
+
+	if err != nil {
+		return nil, rateLimit, err
+	}
+
+	var predictions []Prediction
+	err = client.decode(body, meta.contentType(), &predictions)
+
+	if err != nil {
+		return nil, rateLimit, err
+	}
+
+	return alignPredictions(names, predictions), rateLimit, nil
+}
+
+// chunkNames splits the names into chunks of at most size names
+func chunkNames(names []string, size int) [][]string {
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+
+	var chunks [][]string
+
+	for start := 0; start < len(names); start += size {
+		end := start + size
+
+		if end > len(names) {
+			end = len(names)
+		}
+
+		chunks = append(chunks, names[start:end])
+	}
+
+	return chunks
+}
+
+// dedupNames removes case-insensitive duplicates, keeping the first-seen casing
+// The returned indexes map each input name to its position in the unique names.
+func dedupNames(names []string) ([]string, []int) {
+	var unique []string
+	seen := make(map[string]int, len(names))
+	indexes := make([]int, len(names))
+
+	for i, name := range names {
+		key := strings.ToLower(name)
+		index, ok := seen[key]
+
+		if !ok {
+			index = len(unique)
+			seen[key] = index
+			unique = append(unique, name)
+		}
+
+		indexes[i] = index
+	}
+
+	return unique, indexes
+}
+
+// fanOutPredictions expands the predictions for unique names back to the original names
+func fanOutPredictions(predictions []Prediction, names []string, indexes []int) []Prediction {
+	result := make([]Prediction, len(names))
+
+	for i, index := range indexes {
+		if index < len(predictions) {
+			result[i] = predictions[index]
+		} else {
+			result[i] = Prediction{Name: names[i]}
+		}
+	}
+
+	return result
+}
+
+// placeholderPredictions returns a prediction with only the name set for each name, keeping failed chunks aligned with the input
+func placeholderPredictions(names []string) []Prediction {
+	predictions := make([]Prediction, len(names))
+
+	for i, name := range names {
+		predictions[i] = Prediction{Name: name}
+	}
+
+	return predictions
+}
+
+// alignPredictions orders the predictions to match the names, matching case-insensitively
+// The API does not guarantee the order of batch responses, and names without a prediction get one with only the name set.
+func alignPredictions(names []string, predictions []Prediction) []Prediction {
+	byName := make(map[string][]Prediction, len(predictions))
+
+	for _, prediction := range predictions {
+		key := strings.ToLower(prediction.Name)
+		byName[key] = append(byName[key], prediction)
+	}
+
+	aligned := make([]Prediction, len(names))
+
+	for i, name := range names {
+		key := strings.ToLower(name)
+		matches := byName[key]
+
+		if len(matches) == 0 {
+			aligned[i] = Prediction{Name: name}
+			continue
+		}
+
+		aligned[i] = matches[0]
+		byName[key] = matches[1:]
+	}
+
+	return aligned
+}
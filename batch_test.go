@@ -0,0 +1,484 @@
+package agify
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchHandler responds to batch requests with a prediction for every name
+func batchHandler(t *testing.T, onRequest func(names []string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		names := r.URL.Query()["name[]"]
+
+		if onRequest != nil {
+			onRequest(names)
+		}
+
+		predictions := make([]string, len(names))
+
+		for i, name := range names {
+			predictions[i] = fmt.Sprintf(`{"name":%q,"age":%d,"count":100}`, name, 20+i)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("[" + strings.Join(predictions, ",") + "]"))
+	}
+}
+
+// makeNames creates a list of unique names
+func makeNames(count int) []string {
+	names := make([]string, count)
+
+	for i := range names {
+		names[i] = fmt.Sprintf("name%d", i)
+	}
+
+	return names
+}
+
+func TestShouldChunkBatchPrediction(t *testing.T) {
+	var chunks []int
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		chunks = append(chunks, len(names))
+	}))
+	defer server.Close()
+
+	names := makeNames(23)
+	client := NewClient(WithUrl(server.URL))
+	result, rateLimit, err := client.BatchPredict(names)
+
+	assert.Nil(t, err)
+	assert.Nil(t, rateLimit)
+	assert.Equal(t, []int{10, 10, 3}, chunks)
+	assert.Len(t, result, 23)
+
+	for i, prediction := range result {
+		assert.Equal(t, names[i], prediction.Name)
+	}
+}
+
+func TestShouldUseConfiguredChunkSize(t *testing.T) {
+	var chunks []int
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		chunks = append(chunks, len(names))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithChunkSize(5))
+	result, _, err := client.BatchPredict(makeNames(12))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{5, 5, 2}, chunks)
+	assert.Len(t, result, 12)
+}
+
+func TestShouldStopBatchOnFailedChunk(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Rate-Limit-Remaining", fmt.Sprint(10-requests))
+
+		if requests == 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{ "error": "Request limit reached" }`))
+			return
+		}
+
+		batchHandler(t, nil)(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, rateLimit, err := client.BatchPredict(makeNames(30))
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 8, rateLimit.Remaining)
+}
+
+func TestShouldDedupBatchNames(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithDedup(true))
+	result, _, err := client.BatchPredict([]string{"Mike", "mike", "MIKE"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Mike"}, requested)
+	assert.Len(t, result, 3)
+
+	for _, prediction := range result {
+		assert.Equal(t, "Mike", prediction.Name)
+	}
+}
+
+func TestShouldPreserveInputOrderWhenDeduping(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithDedup(true))
+	result, _, err := client.BatchPredict([]string{"jane", "Mike", "JANE", "mike"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"jane", "Mike"}, requested)
+	assert.Len(t, result, 4)
+	assert.Equal(t, "jane", result[2].Name)
+	assert.Equal(t, "Mike", result[3].Name)
+}
+
+func TestShouldNotDedupByDefault(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.BatchPredict([]string{"Mike", "mike"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Mike", "mike"}, requested)
+	assert.Len(t, result, 2)
+}
+
+func TestShouldLimitConcurrentBatchPrediction(t *testing.T) {
+	var maxInFlight int32
+	server := httptest.NewServer(inFlightHandler(t, &maxInFlight))
+	defer server.Close()
+
+	names := makeNames(40)
+	client := NewClient(WithUrl(server.URL), WithConcurrency(2))
+	result, _, err := client.ConcurrentBatchPredict(context.Background(), names)
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 40)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+
+	for i, prediction := range result {
+		assert.Equal(t, names[i], prediction.Name)
+	}
+}
+
+func TestShouldReturnLowestRemainingFromConcurrentBatch(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := atomic.AddInt32(&requests, 1)
+		w.Header().Set("X-Rate-Limit-Remaining", fmt.Sprint(100-request))
+		batchHandler(t, nil)(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	_, rateLimit, err := client.ConcurrentBatchPredict(context.Background(), makeNames(30))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 97, rateLimit.Remaining)
+}
+
+func TestShouldCancelConcurrentBatchWhenRateLimited(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{ "error": "Request limit reached" }`))
+			return
+		}
+
+		batchHandler(t, nil)(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithConcurrency(1))
+	result, _, err := client.ConcurrentBatchPredict(context.Background(), makeNames(40))
+
+	var apiErr *APIError
+	assert.Nil(t, result)
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestShouldReturnBatchPredictionsAsSlice(t *testing.T) {
+	server := httptest.NewServer(batchHandler(t, nil))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.BatchPredict([]string{"michael", "matthew", "jane"})
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 3)
+
+	count := 0
+
+	for _, prediction := range result {
+		assert.NotEmpty(t, prediction.Name)
+		count++
+	}
+
+	assert.Equal(t, 3, count)
+}
+
+func TestShouldDecompressGzipBatchResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusOK)
+
+		writer := gzip.NewWriter(w)
+		writer.Write([]byte(`[{"name":"michael","age":70,"count":233482},{"name":"matthew","age":36,"count":34742}]`))
+		writer.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCompression(true))
+	result, _, err := client.BatchPredict([]string{"michael", "matthew"})
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 36, result[1].Age)
+}
+
+func TestShouldHandleUncompressedResponseWithCompression(t *testing.T) {
+	server := httptest.NewServer(batchHandler(t, nil))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCompression(true))
+	result, _, err := client.BatchPredict([]string{"michael", "matthew"})
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+}
+
+func TestShouldAlignShuffledBatchPredictions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"name":"jane","age":36,"count":35010},{"name":"michael","age":70,"count":233482},{"name":"matthew","age":36,"count":34742}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.BatchPredict([]string{"Michael", "matthew", "JANE"})
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 3)
+	assert.Equal(t, "michael", result[0].Name)
+	assert.Equal(t, 70, result[0].Age)
+	assert.Equal(t, "matthew", result[1].Name)
+	assert.Equal(t, "jane", result[2].Name)
+}
+
+func TestShouldFillMissingBatchPredictions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"name":"michael","age":70,"count":233482}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.BatchPredict([]string{"xyzzy", "michael"})
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, Prediction{Name: "xyzzy"}, result[0])
+	assert.Equal(t, 70, result[1].Age)
+}
+
+func TestShouldMergeRateLimitsAcrossChunks(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Rate-Limit-Remaining", fmt.Sprint(100-requests*10))
+		w.Header().Set("X-Rate-Reset", fmt.Sprint([]int{30, 90, 60}[requests-1]))
+		batchHandler(t, nil)(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	_, rateLimit, err := client.BatchPredict(makeNames(25))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 70, rateLimit.Remaining)
+	assert.Equal(t, 90*time.Second, rateLimit.Reset)
+}
+
+// trackInFlight answers each request slowly with next and records the most requests seen in flight at once
+func trackInFlight(maxInFlight *int32, next http.HandlerFunc) http.HandlerFunc {
+	var inFlight int32
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt32(maxInFlight)
+
+			if current <= max || atomic.CompareAndSwapInt32(maxInFlight, max, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		next(w, r)
+	}
+}
+
+// inFlightHandler answers batch requests slowly and records the most requests seen in flight at once
+func inFlightHandler(t *testing.T, maxInFlight *int32) http.HandlerFunc {
+	return trackInFlight(maxInFlight, batchHandler(t, nil))
+}
+
+func TestShouldDefaultBatchConcurrencyToClientConcurrency(t *testing.T) {
+	assert.Equal(t, 8, NewClient(WithConcurrency(8)).batchWorkers())
+	assert.Equal(t, defaultConcurrency, NewClient().batchWorkers())
+	assert.Equal(t, 2, NewClient(WithConcurrency(8), WithMaxBatchConcurrency(2)).batchWorkers())
+}
+
+func TestShouldLimitChunksOfSingleBatch(t *testing.T) {
+	var maxInFlight int32
+	server := httptest.NewServer(inFlightHandler(t, &maxInFlight))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithConcurrency(8), WithMaxBatchConcurrency(2))
+	result, _, err := client.ConcurrentBatchPredict(context.Background(), makeNames(40))
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 40)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestShouldShareConcurrencyAcrossBatches(t *testing.T) {
+	var maxInFlight int32
+	server := httptest.NewServer(inFlightHandler(t, &maxInFlight))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithConcurrency(3), WithMaxBatchConcurrency(2))
+	errs := make(chan error, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _, err := client.ConcurrentBatchPredict(context.Background(), makeNames(40))
+			errs <- err
+		}()
+	}
+
+	assert.Nil(t, <-errs)
+	assert.Nil(t, <-errs)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(3))
+}
+
+// slowAfterFirstHandler answers the first batch request immediately and stalls every later request
+func slowAfterFirstHandler(t *testing.T) http.HandlerFunc {
+	var requests int32
+
+	return batchHandler(t, func(names []string) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+	})
+}
+
+func TestShouldReturnCompletedChunksWhenDeadlineExpires(t *testing.T) {
+	server := httptest.NewServer(slowAfterFirstHandler(t))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	names := makeNames(30)
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.BatchPredictContext(ctx, names)
+
+	var incomplete *IncompleteBatchError
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, errors.As(err, &incomplete))
+	assert.Len(t, result, 10)
+	assert.Equal(t, names[0], result[0].Name)
+	assert.Len(t, incomplete.Failed, 20)
+	assert.Equal(t, names[10], incomplete.Failed[0].Name)
+}
+
+func TestShouldReturnCompletedDedupedNamesWhenDeadlineExpires(t *testing.T) {
+	server := httptest.NewServer(slowAfterFirstHandler(t))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	names := append(makeNames(20), "name0", "name15")
+	client := NewClient(WithUrl(server.URL), WithDedup(true))
+	result, _, err := client.BatchPredictContext(ctx, names)
+
+	var incomplete *IncompleteBatchError
+	assert.True(t, errors.As(err, &incomplete))
+	assert.Len(t, result, 11)
+	assert.Equal(t, "name0", result[10].Name)
+	assert.Len(t, incomplete.Failed, 11)
+}
+
+func TestShouldKeepChunkErrorsWhenCancelledWhileCollecting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{ "error": "failed" }`))
+		case 2:
+			batchHandler(t, nil)(w, r)
+		default:
+			cancel()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	names := makeNames(40)
+	client := NewClient(WithUrl(server.URL), WithCollectErrors(true))
+	result, _, err := client.BatchPredictContext(ctx, names)
+
+	var incomplete *IncompleteBatchError
+	assert.True(t, errors.As(err, &incomplete))
+	assert.Len(t, result, 10)
+	assert.Equal(t, names[10], result[0].Name)
+	assert.Len(t, incomplete.Failed, 30)
+	assert.True(t, hasStatusCode(incomplete.Failed[0].Err, http.StatusInternalServerError))
+	assert.Equal(t, names[20], incomplete.Failed[10].Name)
+	assert.ErrorIs(t, incomplete.Failed[10].Err, context.Canceled)
+}
+
+func TestShouldReturnCompletedConcurrentChunksWhenDeadlineExpires(t *testing.T) {
+	server := httptest.NewServer(slowAfterFirstHandler(t))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	client := NewClient(WithUrl(server.URL), WithMaxBatchConcurrency(1))
+	result, _, err := client.ConcurrentBatchPredict(ctx, makeNames(30))
+
+	var incomplete *IncompleteBatchError
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, errors.As(err, &incomplete))
+	assert.Len(t, result, 10)
+	assert.Len(t, incomplete.Failed, 20)
+}
@@ -0,0 +1,37 @@
+package agify
+
+import "context"
+
+// PredictBestCountry predicts the age of a name in each candidate country and returns the prediction with the highest count
+// Countries are queried in order and the search stops early once the rate limit reports no requests remaining.
+// Without any candidate countries the name is predicted without a country.
+func (client *Client) PredictBestCountry(ctx context.Context, name string, countries []string) (*Prediction, *RateLimit, error) {
+	if len(countries) == 0 {
+		return client.PredictContext(ctx, name)
+	}
+
+	var best *Prediction
+	var rateLimit *RateLimit
+
+	for _, country := range countries {
+		prediction, meta, err := client.PredictWithMeta(ctx, name, country)
+
+		if meta != nil {
+			rateLimit = meta.RateLimit
+		}
+
+		if err != nil {
+			return nil, rateLimit, err
+		}
+
+		if prediction != nil && (best == nil || prediction.Count > best.Count) {
+			best = prediction
+		}
+
+		if rateLimit != nil && rateLimit.RawRemaining != "" && rateLimit.Remaining <= 0 {
+			break
+		}
+	}
+
+	return best, rateLimit, nil
+}
@@ -0,0 +1,56 @@
+package agify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countryCountHandler responds with a prediction whose count depends on the country
+func countryCountHandler(counts map[string]int, remaining *int, requests *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		country := r.URL.Query().Get("country_id")
+		*requests = append(*requests, country)
+
+		if remaining != nil {
+			*remaining--
+			w.Header().Set("X-Rate-Limit-Remaining", fmt.Sprint(*remaining))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"name":"michael","age":70,"count":%d,"country_id":"%s"}`, counts[country], country)
+	}
+}
+
+func TestShouldPickCountryWithHighestCount(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(countryCountHandler(map[string]int{"US": 100, "GB": 500, "DE": 50}, nil, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	prediction, _, err := client.PredictBestCountry(context.Background(), "michael", []string{"US", "GB", "DE"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "GB", prediction.Country)
+	assert.Equal(t, 500, prediction.Count)
+	assert.Equal(t, []string{"US", "GB", "DE"}, requests)
+}
+
+func TestShouldStopBestCountryWhenQuotaExhausted(t *testing.T) {
+	var requests []string
+	remaining := 2
+	server := httptest.NewServer(countryCountHandler(map[string]int{"US": 100, "GB": 500, "DE": 900}, &remaining, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	prediction, rateLimit, err := client.PredictBestCountry(context.Background(), "michael", []string{"US", "GB", "DE"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "GB", prediction.Country)
+	assert.Equal(t, 0, rateLimit.Remaining)
+	assert.Equal(t, []string{"US", "GB"}, requests)
+}
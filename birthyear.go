@@ -0,0 +1,44 @@
+package agify
+
+import (
+	"context"
+	"time"
+)
+
+// BirthYear returns the birth year implied by the predicted age at now, or false if the API did not predict an age
+// Pass the time from the client's clock, or a fixed time in tests.
+func (prediction *Prediction) BirthYear(now time.Time) (int, bool) {
+	if !prediction.HasAge() {
+		return 0, false
+	}
+
+	return now.Year() - prediction.Age, true
+}
+
+// PredictAgainstBirthYear returns the prediction for a name and how far the predicted age is from the age implied by birthYear
+// The implied age uses the current year from the client clock. The delta is -1 when the API has no age for the name, including a nil prediction from WithNotFoundAsNil.
+func (client *Client) PredictAgainstBirthYear(ctx context.Context, name string, birthYear int) (*Prediction, int, *RateLimit, error) {
+	prediction, rateLimit, err := client.PredictContext(ctx, name)
+
+	if err != nil {
+		return nil, 0, rateLimit, err
+	}
+
+	if prediction == nil {
+		return nil, -1, rateLimit, nil
+	}
+
+	predictedYear, ok := prediction.BirthYear(client.clock.Now())
+
+	if !ok {
+		return prediction, -1, rateLimit, nil
+	}
+
+	delta := birthYear - predictedYear
+
+	if delta < 0 {
+		delta = -delta
+	}
+
+	return prediction, delta, rateLimit, nil
+}
@@ -0,0 +1,67 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldComputeDeltaAgainstBirthYear(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithClock(clock))
+
+	prediction, delta, _, err := client.PredictAgainstBirthYear(context.Background(), "michael", 1960)
+	assert.Nil(t, err)
+	assert.Equal(t, 70, prediction.Age)
+	assert.Equal(t, 8, delta)
+
+	_, delta, _, err = client.PredictAgainstBirthYear(context.Background(), "michael", 1942)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, delta)
+}
+
+func TestShouldReturnNegativeDeltaWithoutAge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"xyz","age":null,"count":0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	_, delta, _, err := client.PredictAgainstBirthYear(context.Background(), "xyz", 1960)
+
+	assert.Nil(t, err)
+	assert.Equal(t, -1, delta)
+}
+
+func TestShouldReturnImpliedBirthYear(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	prediction := Prediction{Age: 70, Found: true}
+
+	year, ok := prediction.BirthYear(now)
+	assert.True(t, ok)
+	assert.Equal(t, 1954, year)
+
+	prediction = Prediction{Age: 0, Found: true}
+	year, ok = prediction.BirthYear(now)
+	assert.True(t, ok)
+	assert.Equal(t, 2024, year)
+}
+
+func TestShouldNotReturnBirthYearWithoutAge(t *testing.T) {
+	prediction := Prediction{Name: "unknown"}
+	year, ok := prediction.BirthYear(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.False(t, ok)
+	assert.Equal(t, 0, year)
+}
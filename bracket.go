@@ -0,0 +1,69 @@
+package agify
+
+import (
+	"strconv"
+	"time"
+)
+
+// AgeBracketBounds are the ascending ages at which each age bracket after the first starts
+// The default brackets are 0-17, 18-24, 25-34, 35-44, 45-54, 55-64 and 65+.
+var AgeBracketBounds = []int{18, 25, 35, 45, 55, 65}
+
+// generation is a named range of birth years, starting at the first year
+type generation struct {
+	name      string
+	firstYear int
+}
+
+// generations are ordered by first birth year, following the Pew Research Center definitions
+var generations = []generation{
+	{"Greatest Generation", 0},
+	{"Silent Generation", 1928},
+	{"Baby Boomer", 1946},
+	{"Gen X", 1965},
+	{"Millennial", 1981},
+	{"Gen Z", 1997},
+	{"Gen Alpha", 2013},
+}
+
+// AgeBracket returns the label of the bracket containing the predicted age, such as "25-34" or "65+"
+// An empty string is returned when the API did not predict an age.
+func (prediction *Prediction) AgeBracket() string {
+	if !prediction.HasAge() {
+		return ""
+	}
+
+	lower := 0
+
+	for _, bound := range AgeBracketBounds {
+		if prediction.Age < bound {
+			return strconv.Itoa(lower) + "-" + strconv.Itoa(bound-1)
+		}
+
+		lower = bound
+	}
+
+	return strconv.Itoa(lower) + "+"
+}
+
+// Generation returns the generation of the birth year implied by the predicted age at now, such as "Millennial"
+// An empty string is returned when the API did not predict an age.
+func (prediction *Prediction) Generation(now time.Time) string {
+	birthYear, ok := prediction.BirthYear(now)
+
+	if !ok {
+		return ""
+	}
+
+	name := generations[0].name
+
+	for _, generation := range generations {
+		if birthYear < generation.firstYear {
+			break
+		}
+
+		name = generation.name
+	}
+
+	return name
+}
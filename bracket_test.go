@@ -0,0 +1,69 @@
+package agify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldReturnAgeBracket(t *testing.T) {
+	tests := []struct {
+		age     int
+		bracket string
+	}{
+		{0, "0-17"},
+		{17, "0-17"},
+		{18, "18-24"},
+		{24, "18-24"},
+		{25, "25-34"},
+		{64, "55-64"},
+		{65, "65+"},
+		{101, "65+"},
+	}
+
+	for _, test := range tests {
+		prediction := Prediction{Age: test.age, Found: true}
+		assert.Equal(t, test.bracket, prediction.AgeBracket(), "age %d", test.age)
+	}
+
+	assert.Equal(t, "", (&Prediction{}).AgeBracket())
+}
+
+func TestShouldUseConfiguredAgeBrackets(t *testing.T) {
+	defer func(bounds []int) { AgeBracketBounds = bounds }(AgeBracketBounds)
+	AgeBracketBounds = []int{21, 50}
+
+	assert.Equal(t, "0-20", (&Prediction{Age: 20, Found: true}).AgeBracket())
+	assert.Equal(t, "21-49", (&Prediction{Age: 21, Found: true}).AgeBracket())
+	assert.Equal(t, "50+", (&Prediction{Age: 50, Found: true}).AgeBracket())
+}
+
+func TestShouldReturnGeneration(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		birthYear  int
+		generation string
+	}{
+		{1920, "Greatest Generation"},
+		{1928, "Silent Generation"},
+		{1945, "Silent Generation"},
+		{1946, "Baby Boomer"},
+		{1964, "Baby Boomer"},
+		{1965, "Gen X"},
+		{1980, "Gen X"},
+		{1981, "Millennial"},
+		{1996, "Millennial"},
+		{1997, "Gen Z"},
+		{2012, "Gen Z"},
+		{2013, "Gen Alpha"},
+	}
+
+	for _, test := range tests {
+		prediction := Prediction{Age: now.Year() - test.birthYear, Found: true}
+		assert.Equal(t, test.generation, prediction.Generation(now), "born %d", test.birthYear)
+	}
+
+	assert.Equal(t, "", (&Prediction{}).Generation(now))
+}
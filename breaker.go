@@ -0,0 +1,116 @@
+package agify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops requests after consecutive failures until a cooldown has elapsed
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     Clock
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+	trial    bool
+}
+
+// newCircuitBreaker creates a circuit breaker, or returns nil if the threshold disables it
+func newCircuitBreaker(threshold int, cooldown time.Duration, clock Clock) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		clock:     clock,
+	}
+}
+
+// allow returns ErrCircuitOpen if a request should not be made
+// Once the cooldown has elapsed a single trial request is allowed through.
+func (breaker *circuitBreaker) allow() error {
+	if breaker == nil {
+		return nil
+	}
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	if !breaker.open {
+		return nil
+	}
+
+	if breaker.trial || breaker.clock.Now().Sub(breaker.openedAt) < breaker.cooldown {
+		return ErrCircuitOpen
+	}
+
+	breaker.trial = true
+	return nil
+}
+
+// abandon frees up the trial for a request that was allowed but never sent, leaving the state unchanged
+func (breaker *circuitBreaker) abandon() {
+	if breaker == nil {
+		return
+	}
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.trial = false
+}
+
+// record updates the breaker with the result of a request
+func (breaker *circuitBreaker) record(err error) {
+	if breaker == nil {
+		return
+	}
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	// A cancelled request says nothing about the API's health either way
+	if errors.Is(err, context.Canceled) {
+		breaker.trial = false
+		return
+	}
+
+	if !isBreakerFailure(err) {
+		breaker.failures = 0
+		breaker.open = false
+		breaker.trial = false
+		return
+	}
+
+	breaker.failures++
+
+	if breaker.trial || breaker.failures >= breaker.threshold {
+		breaker.open = true
+		breaker.openedAt = breaker.clock.Now()
+		breaker.trial = false
+	}
+}
+
+// isBreakerFailure returns true if the error suggests the API is unavailable
+// Client errors such as an invalid API key do not count.
+func isBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return true
+}
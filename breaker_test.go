@@ -0,0 +1,126 @@
+package agify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldOpenCircuitAfterConsecutiveFailures(t *testing.T) {
+	requests := 0
+	healthy := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if !healthy {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{ "error": "Internal server error" }`))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithCircuitBreaker(5, time.Minute), WithClock(clock))
+
+	for i := 0; i < 5; i++ {
+		_, _, err := client.Predict("michael")
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+
+	_, _, err := client.Predict("michael")
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 5, requests)
+
+	healthy = true
+	clock.Advance(time.Minute)
+
+	result, _, err := client.Predict("michael")
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+
+	_, _, err = client.Predict("michael")
+	assert.Nil(t, err)
+	assert.Equal(t, 7, requests)
+}
+
+func TestShouldReopenCircuitWhenTrialFails(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker := newCircuitBreaker(1, time.Minute, clock)
+	failure := &APIError{StatusCode: http.StatusServiceUnavailable}
+
+	breaker.record(failure)
+	assert.Equal(t, ErrCircuitOpen, breaker.allow())
+
+	clock.Advance(time.Minute)
+	assert.Nil(t, breaker.allow())
+	assert.Equal(t, ErrCircuitOpen, breaker.allow())
+
+	breaker.record(failure)
+	assert.Equal(t, ErrCircuitOpen, breaker.allow())
+}
+
+func TestShouldNotCountClientErrorsAsFailures(t *testing.T) {
+	breaker := newCircuitBreaker(1, time.Minute, newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	breaker.record(&APIError{StatusCode: http.StatusUnauthorized})
+
+	assert.Nil(t, breaker.allow())
+}
+
+func TestShouldIgnoreRequestsThatNeverReachedServer(t *testing.T) {
+	breaker := newCircuitBreaker(2, time.Minute, newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	failure := &APIError{StatusCode: http.StatusInternalServerError}
+
+	breaker.record(failure)
+	breaker.record(context.Canceled)
+	breaker.abandon()
+	assert.Nil(t, breaker.allow())
+
+	breaker.record(failure)
+	assert.Equal(t, ErrCircuitOpen, breaker.allow())
+}
+
+func TestShouldNotCloseCircuitWhenTrialIsCancelled(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker := newCircuitBreaker(1, time.Minute, clock)
+	breaker.record(&APIError{StatusCode: http.StatusServiceUnavailable})
+
+	clock.Advance(time.Minute)
+	assert.Nil(t, breaker.allow())
+
+	breaker.record(context.Canceled)
+	assert.Nil(t, breaker.allow())
+	assert.Equal(t, ErrCircuitOpen, breaker.allow())
+}
+
+func TestShouldNotCountCancelledCallBetweenFailures(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusInternalServerError, 10, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCircuitBreaker(2, time.Minute))
+
+	_, _, err := client.Predict("michael")
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = client.PredictContext(ctx, "michael")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, _, err = client.Predict("michael")
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+
+	_, _, err = client.Predict("michael")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, requests)
+}
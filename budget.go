@@ -0,0 +1,60 @@
+package agify
+
+import "sync"
+
+// retryBudgetTokens is the number of retries the budget holds, which it starts with and cannot exceed
+const retryBudgetTokens = 10
+
+// retryBudget limits retries to a ratio of successful requests, following gRPC retry throttling
+// Every retry spends a token and every success earns ratio tokens, up to retryBudgetTokens.
+type retryBudget struct {
+	ratio float64
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// newRetryBudget creates a retry budget, or returns nil if the ratio disables it
+func newRetryBudget(ratio float64) *retryBudget {
+	if ratio <= 0 {
+		return nil
+	}
+
+	return &retryBudget{
+		ratio:  ratio,
+		tokens: retryBudgetTokens,
+	}
+}
+
+// withdraw spends a token for a retry, returning false if the budget is exhausted
+func (budget *retryBudget) withdraw() bool {
+	if budget == nil {
+		return true
+	}
+
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+
+	if budget.tokens < 1 {
+		return false
+	}
+
+	budget.tokens--
+	return true
+}
+
+// deposit earns tokens for a successful request
+func (budget *retryBudget) deposit() {
+	if budget == nil {
+		return
+	}
+
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+
+	budget.tokens += budget.ratio
+
+	if budget.tokens > retryBudgetTokens {
+		budget.tokens = retryBudgetTokens
+	}
+}
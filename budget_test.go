@@ -0,0 +1,59 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldStopRetryingWhenBudgetDrained(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithRetry(3, 0), WithRetryServerErrors(true), WithRetryBudget(0.1))
+
+	for i := 0; i < 10; i++ {
+		_, _, err := client.Predict("michael")
+		assert.True(t, hasStatusCode(err, http.StatusServiceUnavailable))
+	}
+
+	// Each call makes one request plus retries until the 10 retries in the budget are spent
+	assert.Equal(t, int32(20), atomic.LoadInt32(&requests))
+}
+
+func TestShouldRefillRetryBudgetOnSuccess(t *testing.T) {
+	budget := newRetryBudget(0.5)
+
+	for i := 0; i < retryBudgetTokens; i++ {
+		assert.True(t, budget.withdraw())
+	}
+
+	assert.False(t, budget.withdraw())
+
+	budget.deposit()
+	assert.False(t, budget.withdraw())
+
+	budget.deposit()
+	assert.True(t, budget.withdraw())
+
+	for i := 0; i < 100; i++ {
+		budget.deposit()
+	}
+
+	assert.Equal(t, float64(retryBudgetTokens), budget.tokens)
+}
+
+func TestShouldNotLimitRetriesWithoutBudget(t *testing.T) {
+	var budget *retryBudget
+
+	assert.Nil(t, newRetryBudget(0))
+	assert.True(t, budget.withdraw())
+	budget.deposit()
+}
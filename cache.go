@@ -0,0 +1,138 @@
+package agify
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// predictionCache is a concurrency-safe cache of predictions keyed by name and country
+	predictionCache struct {
+		ttl     time.Duration
+		clock   Clock
+		mu      sync.Mutex
+		entries map[string]cacheEntry
+
+		// stop is closed to stop the janitor, which closes done once it has returned
+		stop     chan struct{}
+		done     chan struct{}
+		stopOnce sync.Once
+	}
+
+	// cacheEntry is a cached prediction and the time it expires
+	cacheEntry struct {
+		prediction Prediction
+		expires    time.Time
+	}
+)
+
+// newPredictionCache creates a cache, or returns nil if the ttl disables caching
+func newPredictionCache(ttl time.Duration, clock Clock) *predictionCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	cache := &predictionCache{
+		ttl:     ttl,
+		clock:   clock,
+		entries: make(map[string]cacheEntry),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go cache.janitor(ttl)
+
+	return cache
+}
+
+// janitor evicts expired entries every interval until the cache is closed
+func (cache *predictionCache) janitor(interval time.Duration) {
+	defer close(cache.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cache.evictExpired()
+		case <-cache.stop:
+			return
+		}
+	}
+}
+
+// evictExpired removes every expired entry
+func (cache *predictionCache) evictExpired() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := cache.clock.Now()
+
+	for key, entry := range cache.entries {
+		if now.After(entry.expires) {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+// close stops the janitor and waits for it to return, and is safe to call more than once
+func (cache *predictionCache) close() {
+	if cache == nil {
+		return
+	}
+
+	cache.stopOnce.Do(func() {
+		close(cache.stop)
+	})
+
+	<-cache.done
+}
+
+// get returns a copy of the cached prediction, evicting it if it has expired
+func (cache *predictionCache) get(name string, country string) (*Prediction, bool) {
+	if cache == nil {
+		return nil, false
+	}
+
+	key := canonicalKey(name, country)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	if cache.clock.Now().After(entry.expires) {
+		delete(cache.entries, key)
+		return nil, false
+	}
+
+	prediction := entry.prediction
+	return &prediction, true
+}
+
+// set stores a copy of the prediction until the ttl elapses
+func (cache *predictionCache) set(name string, country string, prediction *Prediction) {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.entries[canonicalKey(name, country)] = cacheEntry{
+		prediction: *prediction,
+		expires:    cache.clock.Now().Add(cache.ttl),
+	}
+}
+
+// canonicalKey combines the name and country into the key used by the cache, coalescing and conditional requests
+// The key does not depend on URL encoding or option order, and the country is upper cased since country codes are case insensitive.
+func canonicalKey(name string, country string) string {
+	return name + "\x00" + strings.ToUpper(strings.TrimSpace(country))
+}
@@ -0,0 +1,95 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingHandler responds with a prediction and counts the requests
+func countingHandler(requests *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}
+}
+
+func TestShouldServeCachedPrediction(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(time.Minute))
+
+	first, _, err := client.Predict("michael")
+	assert.Nil(t, err)
+
+	second, rateLimit, err := client.Predict("michael")
+	assert.Nil(t, err)
+	assert.Nil(t, rateLimit)
+	assert.Equal(t, first, second)
+
+	assert.Equal(t, 1, requests)
+}
+
+func TestShouldCacheByNameAndCountry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(time.Minute))
+	client.Predict("michael")
+	client.PredictWithCountry("michael", "US")
+	client.PredictWithCountry("michael", "US")
+
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldExpireCachedPrediction(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(10*time.Millisecond))
+	client.Predict("michael")
+	time.Sleep(20 * time.Millisecond)
+	client.Predict("michael")
+
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldNotCacheByDefault(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	client.Predict("michael")
+	client.Predict("michael")
+
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldUseCanonicalKeyAcrossOptionOrders(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(time.Minute))
+	ctx := context.Background()
+
+	_, _, err := client.PredictOpts(ctx, "michael", WithCallCountry("us"), WithCallTimeout(time.Second))
+	assert.Nil(t, err)
+
+	_, _, err = client.PredictOpts(ctx, "michael", WithCallTimeout(time.Second), WithCallCountry("US"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, canonicalKey("michael", "us"), canonicalKey("michael", " US"))
+	assert.NotEqual(t, canonicalKey("michael", "US"), canonicalKey("michael", ""))
+}
@@ -0,0 +1,71 @@
+package agify
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// CallOption overrides the client configuration for a single call
+	CallOption func(*callOptions)
+
+	// callOptions is a struct used to hold the options for a single call
+	callOptions struct {
+		country   string
+		timeout   time.Duration
+		skipCache bool
+
+		withoutApiKey bool
+	}
+
+	// withoutApiKeyContextKey marks a request context that should not send the API key
+	withoutApiKeyContextKey struct{}
+)
+
+// WithCallCountry sets the country for a single call, following the same rules as an explicit country
+func WithCallCountry(country string) CallOption {
+	return func(options *callOptions) {
+		options.country = country
+	}
+}
+
+// WithCallTimeout limits the whole call, including any retries, to the timeout
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(options *callOptions) {
+		options.timeout = timeout
+	}
+}
+
+// WithSkipCache makes a request even if the prediction is cached
+// The fresh prediction still replaces the cached one.
+func WithSkipCache() CallOption {
+	return func(options *callOptions) {
+		options.skipCache = true
+	}
+}
+
+// WithoutApiKey sends a single call on the keyless public tier even if the client has an API key
+// Combine it with WithSkipCache to make sure the request is made rather than served from the cache.
+func WithoutApiKey() CallOption {
+	return func(options *callOptions) {
+		options.withoutApiKey = true
+	}
+}
+
+// isWithoutApiKey returns true if the request context was marked by WithoutApiKey
+func isWithoutApiKey(ctx context.Context) bool {
+	withoutApiKey, _ := ctx.Value(withoutApiKeyContextKey{}).(bool)
+	return withoutApiKey
+}
+
+// PredictOpts returns the age probability for a name with options that only apply to this call
+func (client *Client) PredictOpts(ctx context.Context, name string, opts ...CallOption) (*Prediction, *RateLimit, error) {
+	options := &callOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	prediction, meta, err := client.predictWithOptions(ctx, name, options)
+	return prediction, meta.rateLimitOrNil(), err
+}
@@ -0,0 +1,82 @@
+package agify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldOverrideCountryPerCall(t *testing.T) {
+	var countries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countries = append(countries, r.URL.Query().Get("country_id"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithDefaultCountry("US"))
+
+	_, _, err := client.PredictOpts(context.Background(), "michael", WithCallCountry("GB"))
+	assert.Nil(t, err)
+
+	_, _, err = client.PredictOpts(context.Background(), "michael")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"GB", "US"}, countries)
+}
+
+func TestShouldSkipCachePerCall(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(time.Minute))
+
+	client.PredictOpts(context.Background(), "michael")
+	client.PredictOpts(context.Background(), "michael")
+	assert.Equal(t, 1, requests)
+
+	prediction, _, err := client.PredictOpts(context.Background(), "michael", WithSkipCache())
+	assert.Nil(t, err)
+	assert.Equal(t, 70, prediction.Age)
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldApplyTimeoutPerCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	_, _, err := client.PredictOpts(context.Background(), "michael", WithCallTimeout(10*time.Millisecond))
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestShouldOmitApiKeyPerCall(t *testing.T) {
+	var apiKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKeys = append(apiKeys, r.URL.Query().Get("apikey"))
+		assert.Equal(t, r.URL.Query().Has("apikey"), apiKeys[len(apiKeys)-1] != "")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithApiKey("secret"))
+
+	_, _, err := client.PredictOpts(context.Background(), "michael", WithoutApiKey())
+	assert.Nil(t, err)
+
+	_, _, err = client.PredictOpts(context.Background(), "michael")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"", "secret"}, apiKeys)
+}
@@ -0,0 +1,38 @@
+package agify
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Clock provides the current time and waiting so time-dependent behavior can be tested
+	// It is used for latency, retry backoff, rate limit waits, cache expiry, circuit breaker cooldowns and API key rotation.
+	Clock interface {
+		// Now returns the current time
+		Now() time.Time
+		// Sleep waits for the duration, returning the context error early if the context is done
+		Sleep(ctx context.Context, d time.Duration) error
+	}
+
+	// realClock is the Clock backed by the system time
+	realClock struct{}
+)
+
+// Now returns the current system time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep waits for the duration or until the context is done
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,135 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock that only moves when advanced or slept on, recording each sleep
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (clock *fakeClock) Now() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.now
+}
+
+func (clock *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	clock.Advance(d)
+
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	clock.sleeps = append(clock.sleeps, d)
+	return nil
+}
+
+func (clock *fakeClock) Advance(d time.Duration) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	clock.now = clock.now.Add(d)
+}
+
+func TestShouldExpireCacheWithFakeClock(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithCache(time.Hour), WithClock(clock))
+
+	client.Predict("michael")
+	clock.Advance(59 * time.Minute)
+	client.Predict("michael")
+	assert.Equal(t, 1, requests)
+
+	clock.Advance(2 * time.Minute)
+	client.Predict("michael")
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldSleepOnClockBetweenRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 2, &requests))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithRetry(3, time.Hour), WithClock(clock))
+
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Len(t, clock.sleeps, 2)
+
+	for _, d := range clock.sleeps {
+		assert.GreaterOrEqual(t, d, 30*time.Minute)
+	}
+}
+
+func TestShouldCloseCircuitAfterFakeCooldown(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker := newCircuitBreaker(1, time.Minute, clock)
+
+	breaker.record(ErrResponseTooLarge)
+	assert.Equal(t, ErrCircuitOpen, breaker.allow())
+
+	clock.Advance(time.Minute)
+	assert.Nil(t, breaker.allow())
+}
+
+func TestShouldInterruptRealSleepWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := realClock{}.Sleep(ctx, time.Hour)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestShouldReturnPromptlyWhenCancelledDuringWaits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{ "error": "Request limit reached" }`))
+	}))
+	defer server.Close()
+
+	clients := map[string]*Client{
+		"retry":      NewClient(WithUrl(server.URL), WithRetry(3, time.Hour)),
+		"rate limit": NewClient(WithUrl(server.URL), WithRateLimitWait(true)),
+	}
+
+	for name, client := range clients {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, _, err := client.PredictContext(ctx, "michael")
+
+		assert.ErrorIs(t, err, context.Canceled, name)
+		assert.Less(t, time.Since(start), time.Second, name)
+	}
+}
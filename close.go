@@ -0,0 +1,11 @@
+package agify
+
+// Close releases the resources held by the client, and is safe to call more than once
+// It stops the cache janitor and closes idle connections of the http client, including one provided with WithClient.
+// The client should not be used after it is closed.
+func (client *Client) Close() error {
+	client.cache.close()
+	client.http.CloseIdleConnections()
+
+	return nil
+}
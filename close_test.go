@@ -0,0 +1,44 @@
+package agify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldCloseIdempotently(t *testing.T) {
+	client := NewClient(WithCache(time.Minute))
+
+	assert.Nil(t, client.Close())
+	assert.Nil(t, client.Close())
+
+	select {
+	case <-client.cache.done:
+	case <-time.After(time.Second):
+		t.Fatal("cache janitor did not stop")
+	}
+}
+
+func TestShouldCloseWithoutOwnedResources(t *testing.T) {
+	client := NewClient()
+
+	assert.Nil(t, client.Close())
+	assert.Nil(t, client.Close())
+}
+
+func TestShouldEvictExpiredEntriesInBackground(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := newPredictionCache(10*time.Millisecond, clock)
+	defer cache.close()
+
+	cache.set("michael", "", &Prediction{Name: "michael", Age: 70})
+	clock.Advance(time.Minute)
+
+	assert.Eventually(t, func() bool {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+
+		return len(cache.entries) == 0
+	}, time.Second, 5*time.Millisecond)
+}
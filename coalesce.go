@@ -0,0 +1,93 @@
+package agify
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// callGroup shares in-flight predictions between concurrent callers with the same key
+	callGroup struct {
+		mu    sync.Mutex
+		calls map[string]*call
+	}
+
+	// call is an in-flight or completed prediction shared by a callGroup
+	call struct {
+		done       chan struct{}
+		waiters    int
+		prediction *Prediction
+		meta       *ResponseMeta
+		err        error
+	}
+)
+
+// newCallGroup creates a call group, or returns nil if coalescing is disabled
+func newCallGroup(enabled bool) *callGroup {
+	if !enabled {
+		return nil
+	}
+
+	return &callGroup{
+		calls: make(map[string]*call),
+	}
+}
+
+// do calls fn, or waits for the in-flight call with the same key and shares its result
+// Each caller receives its own copy of the prediction so it can be modified independently.
+// A waiting caller returns its own context's error if the context is done before the shared call completes.
+func (group *callGroup) do(ctx context.Context, key string, fn func() (*Prediction, *ResponseMeta, error)) (*Prediction, *ResponseMeta, error) {
+	if group == nil {
+		return fn()
+	}
+
+	group.mu.Lock()
+
+	if c, ok := group.calls[key]; ok {
+		c.waiters++
+		group.mu.Unlock()
+
+		select {
+		case <-c.done:
+			return c.result()
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	c := &call{done: make(chan struct{})}
+	group.calls[key] = c
+	group.mu.Unlock()
+
+	c.prediction, c.meta, c.err = fn()
+
+	group.mu.Lock()
+	delete(group.calls, key)
+	group.mu.Unlock()
+
+	close(c.done)
+
+	return c.result()
+}
+
+// waiting returns the number of callers that have joined the in-flight call with the key
+func (group *callGroup) waiting(key string) int {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	if c, ok := group.calls[key]; ok {
+		return c.waiters
+	}
+
+	return 0
+}
+
+// result returns a copy of the shared prediction along with the metadata and error
+func (c *call) result() (*Prediction, *ResponseMeta, error) {
+	if c.prediction == nil {
+		return nil, c.meta, c.err
+	}
+
+	prediction := *c.prediction
+	return &prediction, c.meta, c.err
+}
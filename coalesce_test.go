@@ -0,0 +1,94 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldCoalesceConcurrentPredictions(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCoalescing(true))
+
+	var wg sync.WaitGroup
+	predictions := make([]*Prediction, 20)
+	errs := make([]error, 20)
+
+	for i := range predictions {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			predictions[i], _, errs[i] = client.Predict("michael")
+		}(i)
+	}
+
+	assert.Eventually(t, func() bool {
+		return client.calls.waiting(canonicalKey("michael", "")) == len(predictions)-1
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	for i := range predictions {
+		assert.Nil(t, errs[i])
+		assert.Equal(t, 70, predictions[i].Age)
+	}
+
+	assert.NotSame(t, predictions[0], predictions[1])
+}
+
+func TestShouldNotCoalesceByDefault(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	client.Predict("michael")
+	client.Predict("michael")
+
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldStopWaitingForCoalescedCallWhenCancelled(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(WithUrl(server.URL), WithCoalescing(true))
+	go client.Predict("michael")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := client.PredictContext(ctx, "michael")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,61 @@
+package agify
+
+import "sync"
+
+type (
+	// etagStore is a concurrency-safe store of the ETag and decoded prediction of each response
+	etagStore struct {
+		mu      sync.Mutex
+		entries map[string]etagEntry
+	}
+
+	// etagEntry is a prediction and the ETag of the response it was decoded from
+	etagEntry struct {
+		etag       string
+		prediction Prediction
+	}
+
+	// ifNoneMatchContextKey holds the ETag a request context sends as If-None-Match
+	ifNoneMatchContextKey struct{}
+)
+
+// newEtagStore creates an ETag store, or returns nil if conditional requests are disabled
+func newEtagStore(enabled bool) *etagStore {
+	if !enabled {
+		return nil
+	}
+
+	return &etagStore{
+		entries: make(map[string]etagEntry),
+	}
+}
+
+// get returns the stored entry for the key
+func (store *etagStore) get(key string) (etagEntry, bool) {
+	if store == nil {
+		return etagEntry{}, false
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.entries[key]
+	return entry, ok
+}
+
+// set stores a copy of the prediction with its ETag, removing the entry if there is no ETag
+func (store *etagStore) set(key string, etag string, prediction *Prediction) {
+	if store == nil {
+		return
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if etag == "" {
+		delete(store.entries, key)
+		return
+	}
+
+	store.entries[key] = etagEntry{etag: etag, prediction: *prediction}
+}
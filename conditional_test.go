@@ -0,0 +1,71 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldReturnStoredPredictionOnNotModified(t *testing.T) {
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("X-Rate-Limit-Remaining", "41")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Rate-Limit-Remaining", "42")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithConditionalRequests(true))
+
+	first, rateLimit, err := client.Predict("michael")
+	assert.Nil(t, err)
+	assert.Equal(t, 42, rateLimit.Remaining)
+
+	second, rateLimit, err := client.Predict("michael")
+	assert.Nil(t, err)
+	assert.Equal(t, 41, rateLimit.Remaining)
+	assert.Equal(t, first, second)
+	assert.NotSame(t, first, second)
+
+	assert.Equal(t, []string{"", `"v1"`}, ifNoneMatch)
+}
+
+func TestShouldNotSendIfNoneMatchByDefault(t *testing.T) {
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	client.Predict("michael")
+	client.Predict("michael")
+
+	assert.Equal(t, []string{"", ""}, ifNoneMatch)
+}
+
+func TestShouldTreatUnexpectedNotModifiedAsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithConditionalRequests(true))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, hasStatusCode(err, http.StatusNotModified))
+}
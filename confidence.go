@@ -0,0 +1,61 @@
+package agify
+
+// ConfidenceLevel is a coarse rating of how many data points back a prediction
+type ConfidenceLevel int
+
+const (
+	// ConfidenceLow is a prediction backed by fewer than MediumConfidenceCount data points, or without an age
+	ConfidenceLow ConfidenceLevel = iota
+	// ConfidenceMedium is a prediction backed by at least MediumConfidenceCount data points
+	ConfidenceMedium
+	// ConfidenceHigh is a prediction backed by at least HighConfidenceCount data points
+	ConfidenceHigh
+)
+
+var (
+	// MediumConfidenceCount is the count at which a prediction has medium confidence
+	MediumConfidenceCount = 50
+
+	// HighConfidenceCount is the count at which a prediction has high confidence and Confidence reaches 1
+	HighConfidenceCount = 500
+)
+
+// Confidence returns a score from 0 to 1 that grows linearly with the count until it reaches HighConfidenceCount
+// A prediction without an age has a confidence of 0.
+func (prediction *Prediction) Confidence() float64 {
+	if !prediction.HasAge() || prediction.Count <= 0 || HighConfidenceCount <= 0 {
+		return 0
+	}
+
+	if prediction.Count >= HighConfidenceCount {
+		return 1
+	}
+
+	return float64(prediction.Count) / float64(HighConfidenceCount)
+}
+
+// ConfidenceLevel returns the confidence level for the count using MediumConfidenceCount and HighConfidenceCount
+func (prediction *Prediction) ConfidenceLevel() ConfidenceLevel {
+	switch {
+	case !prediction.HasAge() || prediction.Count <= 0:
+		return ConfidenceLow
+	case prediction.Count >= HighConfidenceCount:
+		return ConfidenceHigh
+	case prediction.Count >= MediumConfidenceCount:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
+
+// String returns the name of the confidence level
+func (level ConfidenceLevel) String() string {
+	switch level {
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceHigh:
+		return "high"
+	default:
+		return "low"
+	}
+}
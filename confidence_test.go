@@ -0,0 +1,72 @@
+package agify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldReturnConfidenceLevelAtThresholds(t *testing.T) {
+	tests := []struct {
+		count int
+		level ConfidenceLevel
+	}{
+		{0, ConfidenceLow},
+		{1, ConfidenceLow},
+		{49, ConfidenceLow},
+		{50, ConfidenceMedium},
+		{499, ConfidenceMedium},
+		{500, ConfidenceHigh},
+		{100000, ConfidenceHigh},
+	}
+
+	for _, test := range tests {
+		prediction := Prediction{Age: 40, Count: test.count, Found: true}
+		assert.Equal(t, test.level, prediction.ConfidenceLevel(), "count %d", test.count)
+	}
+}
+
+func TestShouldScoreConfidenceByCount(t *testing.T) {
+	tests := []struct {
+		count      int
+		confidence float64
+	}{
+		{0, 0},
+		{50, 0.1},
+		{250, 0.5},
+		{500, 1},
+		{5000, 1},
+	}
+
+	for _, test := range tests {
+		prediction := Prediction{Age: 40, Count: test.count, Found: true}
+		assert.InDelta(t, test.confidence, prediction.Confidence(), 0.0001, "count %d", test.count)
+	}
+}
+
+func TestShouldHaveNoConfidenceWithoutAge(t *testing.T) {
+	prediction := Prediction{Count: 1000}
+
+	assert.Equal(t, 0.0, prediction.Confidence())
+	assert.Equal(t, ConfidenceLow, prediction.ConfidenceLevel())
+}
+
+func TestShouldUseConfiguredConfidenceThresholds(t *testing.T) {
+	medium, high := MediumConfidenceCount, HighConfidenceCount
+	defer func() { MediumConfidenceCount, HighConfidenceCount = medium, high }()
+
+	MediumConfidenceCount, HighConfidenceCount = 10, 20
+	prediction := Prediction{Age: 40, Count: 10, Found: true}
+
+	assert.Equal(t, ConfidenceMedium, prediction.ConfidenceLevel())
+	assert.Equal(t, 0.5, prediction.Confidence())
+
+	prediction.Count = 20
+	assert.Equal(t, ConfidenceHigh, prediction.ConfidenceLevel())
+}
+
+func TestShouldNameConfidenceLevels(t *testing.T) {
+	assert.Equal(t, "low", ConfidenceLow.String())
+	assert.Equal(t, "medium", ConfidenceMedium.String())
+	assert.Equal(t, "high", ConfidenceHigh.String())
+}
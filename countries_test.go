@@ -0,0 +1,64 @@
+package agify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldValidateCountry(t *testing.T) {
+	var countries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countries = append(countries, r.URL.Query().Get("country_id"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithValidateCountry(true))
+
+	_, _, err := client.PredictWithCountry("michael", "US")
+	assert.Nil(t, err)
+
+	_, _, err = client.PredictWithCountry("michael", "us")
+	assert.Nil(t, err)
+
+	_, _, err = client.PredictWithCountry("michael", "USA")
+	assert.True(t, errors.Is(err, ErrInvalidCountry))
+	assert.ErrorContains(t, err, `"USA"`)
+
+	_, _, err = client.BatchPredictWithCountry([]string{"michael"}, "XX")
+	assert.True(t, errors.Is(err, ErrInvalidCountry))
+
+	_, _, err = client.Predict("michael")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"US", "US", ""}, countries)
+}
+
+func TestShouldValidateDefaultCountry(t *testing.T) {
+	client := NewClient(WithDefaultCountry("UK"), WithValidateCountry(true))
+	_, err := client.BuildURL("michael", "")
+
+	assert.True(t, errors.Is(err, ErrInvalidCountry))
+}
+
+func TestShouldNotValidateCountryByDefault(t *testing.T) {
+	client := NewClient(WithUrl("http://example.test"))
+	url, err := client.BuildURL("michael", "USA")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://example.test?country_id=USA&name=michael", url)
+}
+
+func TestShouldRecognizeCountryCodes(t *testing.T) {
+	assert.True(t, isCountryCode("US"))
+	assert.True(t, isCountryCode("gb"))
+	assert.False(t, isCountryCode("UK"))
+	assert.False(t, isCountryCode("USA"))
+	assert.False(t, isCountryCode(""))
+	assert.Len(t, isoCountryCodes, 249)
+}
@@ -0,0 +1,74 @@
+package agify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// maxDecodeSnippet is the number of bytes of the body included in a decode error
+const maxDecodeSnippet = 200
+
+// decode unmarshals the response body, rejecting unknown fields when strict decoding is enabled
+// Errors include the content type and the start of the body so an unexpected response such as an HTML error page is obvious.
+func (client *Client) decode(body []byte, contentType string, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+
+	if client.strictDecoding {
+		decoder.DisallowUnknownFields()
+	}
+
+	err := decoder.Decode(v)
+
+	if err == nil && client.strictDecoding {
+		err = checkExtraFields(v)
+	}
+
+	if err != nil {
+		return wrapDecodeError(body, contentType, err)
+	}
+
+	return nil
+}
+
+// wrapDecodeError adds the content type and a truncated snippet of the body to a decode error
+func wrapDecodeError(body []byte, contentType string, err error) error {
+	snippet := body
+
+	if len(snippet) > maxDecodeSnippet {
+		snippet = snippet[:maxDecodeSnippet]
+	}
+
+	return fmt.Errorf("agify: decode failed (content-type %s): %s: %w", contentType, snippet, err)
+}
+
+// checkExtraFields returns an error if a prediction collected unknown fields
+// Prediction has its own UnmarshalJSON, which the decoder does not apply DisallowUnknownFields to.
+func checkExtraFields(v any) error {
+	var predictions []Prediction
+
+	switch decoded := v.(type) {
+	case *Prediction:
+		predictions = []Prediction{*decoded}
+	case *[]Prediction:
+		predictions = *decoded
+	}
+
+	for _, prediction := range predictions {
+		if len(prediction.Extra) == 0 {
+			continue
+		}
+
+		fields := make([]string, 0, len(prediction.Extra))
+
+		for field := range prediction.Extra {
+			fields = append(fields, field)
+		}
+
+		sort.Strings(fields)
+		return fmt.Errorf("json: unknown field %q", fields[0])
+	}
+
+	return nil
+}
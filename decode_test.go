@@ -0,0 +1,76 @@
+package agify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldRejectUnknownFieldsWhenStrict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+		if r.URL.Query().Has("name[]") {
+			w.Write([]byte(`[{"name":"michael","age":70,"count":875,"gender":"male"}]`))
+			return
+		}
+
+		w.Write([]byte(`{"name":"michael","age":70,"count":875,"gender":"male"}`))
+	}))
+	defer server.Close()
+
+	lenient := NewClient(WithUrl(server.URL))
+	strict := NewClient(WithUrl(server.URL), WithStrictDecoding(true))
+
+	_, _, err := lenient.Predict("michael")
+	assert.Nil(t, err)
+
+	_, _, err = lenient.BatchPredict([]string{"michael"})
+	assert.Nil(t, err)
+
+	_, _, err = strict.Predict("michael")
+	assert.ErrorContains(t, err, `unknown field "gender"`)
+
+	_, _, err = strict.BatchPredict([]string{"michael"})
+	assert.ErrorContains(t, err, `unknown field "gender"`)
+}
+
+func TestShouldRejectUnknownNationalizeFieldsWhenStrict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","count":10,"country":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithNationalizeUrl(server.URL), WithStrictDecoding(true))
+	_, _, err := client.Nationalize("michael")
+
+	assert.ErrorContains(t, err, `unknown field "count"`)
+}
+
+func TestShouldIncludeBodySnippetInDecodeError(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("x", 300) + "</body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+
+	_, _, err := client.Predict("michael")
+	assert.ErrorContains(t, err, "agify: decode failed (content-type text/html): "+page[:200]+":")
+	assert.NotContains(t, err.Error(), page[:201])
+
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+
+	_, _, err = client.BatchPredict([]string{"michael"})
+	assert.ErrorContains(t, err, "agify: decode failed (content-type text/html): <html><body>")
+}
@@ -0,0 +1,239 @@
+package agify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrEmptyName is returned when a name is empty or only contains whitespace
+	ErrEmptyName = errors.New("agify: name must not be empty")
+
+	// ErrCircuitOpen is returned without making a request while the circuit breaker is open
+	ErrCircuitOpen = errors.New("agify: circuit breaker is open")
+
+	// ErrResponseTooLarge is returned when a response body exceeds the maximum size
+	ErrResponseTooLarge = errors.New("agify: response body too large")
+
+	// ErrPaymentRequired is wrapped by an APIError with status 402, which indicates a billing issue such as an exhausted paid plan
+	// Unlike a 429 it is never retried because waiting will not restore the quota.
+	ErrPaymentRequired = errors.New("agify: payment required")
+
+	// ErrInvalidApiKey is wrapped by an APIError when agify.io rejects the API key
+	ErrInvalidApiKey = errors.New("agify: invalid api key")
+
+	// ErrLimitReached is wrapped by an APIError when agify.io reports that the request limit was reached
+	ErrLimitReached = errors.New("agify: request limit reached")
+
+	// ErrMissingName is wrapped by an APIError when agify.io reports that the name parameter is missing
+	ErrMissingName = errors.New("agify: missing name parameter")
+
+	// ErrInvalidCountry is returned before making a request when country validation is enabled and the country is not an ISO 3166-1 alpha-2 code
+	ErrInvalidCountry = errors.New("agify: invalid country code")
+
+	// ErrRedirectsDisabled is returned when the API redirects a request and redirects are disabled
+	ErrRedirectsDisabled = errors.New("agify: redirects are disabled")
+)
+
+// messageErrors maps the lower case error messages returned by agify.io to sentinel errors
+var messageErrors = map[string]error{
+	"invalid api key":          ErrInvalidApiKey,
+	"request limit reached":    ErrLimitReached,
+	"too many requests":        ErrLimitReached,
+	"missing 'name' parameter": ErrMissingName,
+}
+
+type (
+	// APIError is returned when the API responds with a non-200 status code
+	APIError struct {
+		// StatusCode is the HTTP status code of the response
+		StatusCode int
+		// Message is the error message returned by the API
+		Message string
+	}
+
+	// errorResponse is the error response from the agify API
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+
+	// MultiError holds the errors from every failed chunk of a batch when errors are collected
+	MultiError struct {
+		// Failed are the names that were not predicted, including chunks cancelled after another chunk was rate limited
+		Failed []FailedName
+
+		errs []error
+	}
+
+	// joinedError holds the errors from the failed chunks of a concurrent batch
+	// It works like errors.Join, which is not used so the module does not need a newer Go version.
+	joinedError struct {
+		errs []error
+	}
+
+	// transportError is an error from the http client, such as a failure to connect
+	transportError struct {
+		method string
+		url    string
+		err    error
+	}
+)
+
+// newAPIError creates an APIError from an error response body
+// The message falls back to the status text when the body is empty or not an agify error.
+func newAPIError(statusCode int, body []byte) *APIError {
+	var errResp errorResponse
+
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error == "" {
+		errResp.Error = http.StatusText(statusCode)
+	}
+
+	return &APIError{StatusCode: statusCode, Message: errResp.Error}
+}
+
+// Error returns the API error message along with the status code
+func (err *APIError) Error() string {
+	return fmt.Sprintf("agify: %s (status %d)", err.Message, err.StatusCode)
+}
+
+// Unwrap returns the sentinel errors for the status code and message, so errors.Is can match them
+// Messages that are not known to agify.io have no sentinel.
+func (err *APIError) Unwrap() []error {
+	var sentinels []error
+
+	if err.StatusCode == http.StatusPaymentRequired {
+		sentinels = append(sentinels, ErrPaymentRequired)
+	}
+
+	if sentinel, ok := messageErrors[strings.ToLower(strings.TrimSpace(err.Message))]; ok {
+		sentinels = append(sentinels, sentinel)
+	}
+
+	return sentinels
+}
+
+// Errors returns the error from each failed chunk in the order the chunks were requested
+func (err *MultiError) Errors() []error {
+	return err.errs
+}
+
+// Error returns the number of errors along with each error message
+func (err *MultiError) Error() string {
+	messages := make([]string, len(err.errs))
+
+	for i, chunkErr := range err.errs {
+		messages[i] = chunkErr.Error()
+	}
+
+	return fmt.Sprintf("agify: %d chunks failed: %s", len(err.errs), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the contained errors, so errors.Is and errors.As match any of them
+func (err *MultiError) Unwrap() []error {
+	return err.errs
+}
+
+// joinErrors combines the errors into one error, or returns nil if there are none
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &joinedError{errs: errs}
+}
+
+// Error returns each error message on its own line
+func (err *joinedError) Error() string {
+	messages := make([]string, len(err.errs))
+
+	for i, chunkErr := range err.errs {
+		messages[i] = chunkErr.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the joined errors, so errors.Is and errors.As match any of them
+func (err *joinedError) Unwrap() []error {
+	return err.errs
+}
+
+// hasStatusCode returns true if the error is an APIError with the status code
+func hasStatusCode(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// wrapTransportError adds the method and URL to an error from the http client
+// The url.Error from the http client is unwrapped because its message includes the API key, which is redacted from the URL.
+func wrapTransportError(method string, rawUrl string, apiKeyParam string, err error) error {
+	var urlErr *url.Error
+
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	return &transportError{method: method, url: redactURL(rawUrl, apiKeyParam), err: err}
+}
+
+// Error returns the method and URL of the request along with the underlying error
+func (err *transportError) Error() string {
+	return fmt.Sprintf("agify: %s %s: %s", err.method, err.url, err.err)
+}
+
+// Unwrap returns the underlying error from the http client
+func (err *transportError) Unwrap() error {
+	return err.err
+}
+
+// isTransportError returns true if the request failed without receiving a response
+func isTransportError(err error) bool {
+	var transportErr *transportError
+	return errors.As(err, &transportErr)
+}
+
+// isConnectError returns true if the request failed because the client could not connect to the API
+// Timeouts and redirect errors do not count, since the server may have received the request.
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		return false
+	}
+
+	return !opErr.Timeout()
+}
+
+// redactedValue replaces the API key in URLs that are logged or returned in errors
+const redactedValue = "***"
+
+// redactURL replaces the value of the API key query parameter in a URL with ***
+// A URL that cannot be parsed has its whole query redacted, since the key cannot be found reliably.
+func redactURL(rawUrl string, param string) string {
+	parsed, err := url.Parse(rawUrl)
+
+	if err != nil {
+		if i := strings.IndexByte(rawUrl, '?'); i >= 0 {
+			return rawUrl[:i+1] + redactedValue
+		}
+
+		return rawUrl
+	}
+
+	values := parsed.Query()
+
+	if !values.Has(param) {
+		return rawUrl
+	}
+
+	values.Set(param, redactedValue)
+	escaped := url.QueryEscape(param) + "="
+	parsed.RawQuery = strings.Replace(values.Encode(), escaped+url.QueryEscape(redactedValue), escaped+redactedValue, 1)
+
+	return parsed.String()
+}
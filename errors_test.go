@@ -0,0 +1,325 @@
+package agify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldReturnAPIErrorWithStatusCode(t *testing.T) {
+	tests := []struct {
+		status  int
+		message string
+	}{
+		{http.StatusUnauthorized, "Invalid API key"},
+		{http.StatusUnprocessableEntity, "Missing 'name' parameter"},
+		{http.StatusTooManyRequests, "Request limit reached"},
+	}
+
+	for _, test := range tests {
+		status, message := test.status, test.message
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(`{ "error": "` + message + `" }`))
+		}))
+
+		client := NewClient(WithUrl(server.URL))
+		_, _, err := client.Predict("michael")
+		server.Close()
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, status, apiErr.StatusCode)
+		assert.Equal(t, message, apiErr.Message)
+	}
+}
+
+func TestShouldWrapTransportErrorWithoutApiKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	address := server.URL
+	server.Close()
+
+	client := NewClient(WithUrl(address), WithApiKey("super-secret"))
+	_, _, err := client.Predict("michael")
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "agify: GET "+address)
+	assert.Contains(t, err.Error(), "name=michael")
+	assert.NotContains(t, err.Error(), "super-secret")
+	assert.NotNil(t, errors.Unwrap(err))
+}
+
+func TestShouldReturnErrPaymentRequiredWithoutRetrying(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusPaymentRequired, 1, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithRetry(3, time.Millisecond), WithRetryServerErrors(true))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, errors.Is(err, ErrPaymentRequired))
+	assert.True(t, hasStatusCode(err, http.StatusPaymentRequired))
+	assert.Equal(t, 1, requests)
+}
+
+func TestShouldReturnNilPredictionForNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Remaining", "99")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{ "error": "Not found" }`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNotFoundAsNil(true))
+	prediction, rateLimit, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Nil(t, prediction)
+	assert.Equal(t, 99, rateLimit.Remaining)
+
+	client = NewClient(WithUrl(server.URL))
+	_, _, err = client.Predict("michael")
+
+	assert.True(t, hasStatusCode(err, http.StatusNotFound))
+}
+
+func TestShouldOnlyTreatNotFoundAsNil(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+		w.Write([]byte(`{ "error": "Gone" }`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNotFoundAsNil(true))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, hasStatusCode(err, http.StatusGone))
+}
+
+func TestShouldUseStatusTextForUnparseableErrorBody(t *testing.T) {
+	tests := []struct {
+		status  int
+		body    string
+		message string
+	}{
+		{http.StatusServiceUnavailable, "", "Service Unavailable"},
+		{http.StatusInternalServerError, "<html>oops</html>", "Internal Server Error"},
+		{http.StatusBadGateway, `{"detail":"upstream"}`, "Bad Gateway"},
+	}
+
+	for _, test := range tests {
+		status, body := test.status, test.body
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}))
+
+		client := NewClient(WithUrl(server.URL))
+		_, _, err := client.Predict("michael")
+		server.Close()
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, status, apiErr.StatusCode)
+		assert.Equal(t, test.message, apiErr.Message)
+	}
+}
+
+func TestShouldMapKnownMessagesToSentinels(t *testing.T) {
+	tests := []struct {
+		status   int
+		message  string
+		sentinel error
+	}{
+		{http.StatusUnauthorized, "Invalid API key", ErrInvalidApiKey},
+		{http.StatusTooManyRequests, "Request limit reached", ErrLimitReached},
+		{http.StatusTooManyRequests, "Too many requests", ErrLimitReached},
+		{http.StatusPaymentRequired, "Request limit reached", ErrLimitReached},
+		{http.StatusUnprocessableEntity, "Missing 'name' parameter", ErrMissingName},
+	}
+
+	for _, test := range tests {
+		err := &APIError{StatusCode: test.status, Message: test.message}
+		assert.True(t, errors.Is(err, test.sentinel), test.message)
+	}
+
+	err := &APIError{StatusCode: http.StatusPaymentRequired, Message: "Request limit reached"}
+	assert.True(t, errors.Is(err, ErrPaymentRequired))
+}
+
+func TestShouldNotMapUnknownMessages(t *testing.T) {
+	err := &APIError{StatusCode: http.StatusBadRequest, Message: "Something else"}
+
+	for _, sentinel := range []error{ErrInvalidApiKey, ErrLimitReached, ErrMissingName, ErrPaymentRequired} {
+		assert.False(t, errors.Is(err, sentinel))
+	}
+}
+
+func TestShouldMatchSentinelFromResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{ "error": "Invalid API key" }`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, errors.Is(err, ErrInvalidApiKey))
+}
+
+// statusByRequestHandler fails batch requests with the status for their request number and answers the rest
+func statusByRequestHandler(t *testing.T, statuses map[int32]int) http.HandlerFunc {
+	var requests int32
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if status, ok := statuses[atomic.AddInt32(&requests, 1)]; ok {
+			w.WriteHeader(status)
+			w.Write([]byte(`{ "error": "failed" }`))
+			return
+		}
+
+		batchHandler(t, nil)(w, r)
+	}
+}
+
+func TestShouldCollectChunkErrorsInMultiError(t *testing.T) {
+	server := httptest.NewServer(statusByRequestHandler(t, map[int32]int{
+		1: http.StatusInternalServerError,
+		3: http.StatusUnprocessableEntity,
+	}))
+	defer server.Close()
+
+	names := makeNames(30)
+	client := NewClient(WithUrl(server.URL), WithCollectErrors(true))
+	result, _, err := client.BatchPredict(names)
+
+	var multiErr *MultiError
+	assert.True(t, errors.As(err, &multiErr))
+	assert.Len(t, multiErr.Errors(), 2)
+	assert.True(t, hasStatusCode(multiErr.Errors()[0], http.StatusInternalServerError))
+	assert.True(t, hasStatusCode(multiErr.Errors()[1], http.StatusUnprocessableEntity))
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Contains(t, err.Error(), "2 chunks failed")
+
+	assert.Len(t, result, 30)
+	assert.False(t, result[0].HasAge())
+	assert.Equal(t, names[0], result[0].Name)
+	assert.True(t, result[10].HasAge())
+	assert.Equal(t, names[10], result[10].Name)
+	assert.False(t, result[20].HasAge())
+
+	assert.Len(t, multiErr.Failed, 20)
+	assert.Equal(t, names[0], multiErr.Failed[0].Name)
+	assert.True(t, hasStatusCode(multiErr.Failed[0].Err, http.StatusInternalServerError))
+	assert.Equal(t, names[20], multiErr.Failed[10].Name)
+	assert.True(t, hasStatusCode(multiErr.Failed[10].Err, http.StatusUnprocessableEntity))
+}
+
+func TestShouldCollectConcurrentChunkErrorsInMultiError(t *testing.T) {
+	server := httptest.NewServer(statusByRequestHandler(t, map[int32]int{
+		1: http.StatusInternalServerError,
+		3: http.StatusUnprocessableEntity,
+	}))
+	defer server.Close()
+
+	names := makeNames(30)
+	client := NewClient(WithUrl(server.URL), WithCollectErrors(true), WithMaxBatchConcurrency(1))
+	result, _, err := client.ConcurrentBatchPredict(context.Background(), names)
+
+	var multiErr *MultiError
+	assert.True(t, errors.As(err, &multiErr))
+	assert.Len(t, multiErr.Errors(), 2)
+	assert.Len(t, multiErr.Failed, 20)
+	assert.Len(t, result, 30)
+	assert.Equal(t, names[20], result[20].Name)
+	assert.True(t, result[10].HasAge())
+}
+
+func TestShouldListChunksCancelledAfterRateLimitAsFailed(t *testing.T) {
+	server := httptest.NewServer(statusByRequestHandler(t, map[int32]int{
+		2: http.StatusTooManyRequests,
+	}))
+	defer server.Close()
+
+	names := makeNames(30)
+	client := NewClient(WithUrl(server.URL), WithCollectErrors(true), WithMaxBatchConcurrency(1))
+	result, _, err := client.ConcurrentBatchPredict(context.Background(), names)
+
+	var multiErr *MultiError
+	assert.True(t, errors.As(err, &multiErr))
+	assert.Len(t, multiErr.Errors(), 1)
+	assert.Len(t, multiErr.Failed, 20)
+	assert.True(t, hasStatusCode(multiErr.Failed[0].Err, http.StatusTooManyRequests))
+	assert.Equal(t, names[20], multiErr.Failed[10].Name)
+	assert.ErrorIs(t, multiErr.Failed[10].Err, context.Canceled)
+
+	assert.Len(t, result, 30)
+	assert.True(t, result[0].HasAge())
+	assert.False(t, result[20].HasAge())
+}
+
+func TestShouldStopAtFirstChunkErrorByDefault(t *testing.T) {
+	server := httptest.NewServer(statusByRequestHandler(t, map[int32]int{
+		1: http.StatusInternalServerError,
+		3: http.StatusUnprocessableEntity,
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.BatchPredict(makeNames(30))
+
+	var multiErr *MultiError
+	assert.Nil(t, result)
+	assert.False(t, errors.As(err, &multiErr))
+	assert.True(t, hasStatusCode(err, http.StatusInternalServerError))
+}
+
+func TestShouldRedactApiKeyFromTransportError(t *testing.T) {
+	address := deadAddress()
+	client := NewClient(WithUrl(address), WithApiKey("super-secret"))
+	_, _, err := client.Predict("michael")
+
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "super-secret")
+	assert.Contains(t, err.Error(), strings.TrimPrefix(address, "http://"))
+	assert.Contains(t, err.Error(), "name=michael")
+	assert.Contains(t, err.Error(), "apikey=***")
+}
+
+func TestShouldRedactURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		param    string
+		redacted string
+	}{
+		{"https://api.agify.io?apikey=secret&name=michael", "apikey", "https://api.agify.io?apikey=***&name=michael"},
+		{"https://api.agify.io?name=michael", "apikey", "https://api.agify.io?name=michael"},
+		{"https://api.agify.io?key=secret&name=michael", "key", "https://api.agify.io?key=***&name=michael"},
+		{"https://api.agify.io/%zz?apikey=secret", "apikey", "https://api.agify.io/%zz?***"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.redacted, redactURL(test.url, test.param))
+	}
+}
+
+func TestShouldJoinChunkErrors(t *testing.T) {
+	assert.Nil(t, joinErrors(nil))
+
+	first := &APIError{StatusCode: http.StatusInternalServerError, Message: "failed"}
+	err := joinErrors([]error{first, context.Canceled})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, hasStatusCode(err, http.StatusInternalServerError))
+	assert.Equal(t, first.Error()+"\n"+context.Canceled.Error(), err.Error())
+}
@@ -0,0 +1,35 @@
+package agify
+
+// EstimateRequests returns how many batch requests BatchPredict would make for the names
+// Empty names are counted unless they are skipped, and duplicates are only counted once when dedup is enabled.
+func (client *Client) EstimateRequests(names []string) int {
+	size := client.chunkSize
+
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+
+	count := len(client.estimateNames(names))
+	return (count + size - 1) / size
+}
+
+// EstimateQuota returns how many units of quota BatchPredict would consume for the names
+// agify.io charges for each name in a batch rather than for each request.
+func (client *Client) EstimateQuota(names []string) int {
+	return len(client.estimateNames(names))
+}
+
+// estimateNames returns the names that would be sent to the API by BatchPredict
+func (client *Client) estimateNames(names []string) []string {
+	if client.skipEmpty {
+		names, _ = client.validateNames(names)
+	}
+
+	queries := client.normalizeNameList(names)
+
+	if client.dedup {
+		queries, _ = dedupNames(queries)
+	}
+
+	return queries
+}
@@ -0,0 +1,47 @@
+package agify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldEstimateRequestsAndQuota(t *testing.T) {
+	tests := []struct {
+		names    int
+		requests int
+	}{
+		{0, 0},
+		{10, 1},
+		{11, 2},
+		{25, 3},
+	}
+
+	client := NewClient()
+
+	for _, test := range tests {
+		names := makeNames(test.names)
+		assert.Equal(t, test.requests, client.EstimateRequests(names))
+		assert.Equal(t, test.names, client.EstimateQuota(names))
+	}
+}
+
+func TestShouldEstimateWithDedup(t *testing.T) {
+	names := append(makeNames(10), "NAME0", "name1", "name10")
+
+	client := NewClient(WithDedup(true))
+	assert.Equal(t, 2, client.EstimateRequests(names))
+	assert.Equal(t, 11, client.EstimateQuota(names))
+
+	client = NewClient()
+	assert.Equal(t, 2, client.EstimateRequests(names))
+	assert.Equal(t, 13, client.EstimateQuota(names))
+}
+
+func TestShouldEstimateWithChunkSizeAndSkipEmpty(t *testing.T) {
+	names := append(makeNames(8), "", " ")
+
+	client := NewClient(WithChunkSize(4), WithSkipEmpty(true))
+	assert.Equal(t, 2, client.EstimateRequests(names))
+	assert.Equal(t, 8, client.EstimateQuota(names))
+}
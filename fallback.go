@@ -0,0 +1,33 @@
+package agify
+
+import "net/url"
+
+// fallbackFor returns the URL rewritten to the fallback base URL, or false if there is no fallback for it
+// Only requests to the agify service are rewritten, keeping their query parameters.
+func (client *Client) fallbackFor(rawUrl string) (string, bool) {
+	if client.fallbackUrl == "" {
+		return "", false
+	}
+
+	requestUrl, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return "", false
+	}
+
+	primary, err := parseBaseUrl(client.serviceUrls[ServiceAgify])
+
+	if err != nil || primary.Scheme != requestUrl.Scheme || primary.Host != requestUrl.Host || primary.Path != requestUrl.Path {
+		return "", false
+	}
+
+	fallback, err := parseBaseUrl(client.fallbackUrl)
+
+	if err != nil {
+		return "", false
+	}
+
+	fallback.RawQuery = requestUrl.RawQuery
+
+	return fallback.String(), true
+}
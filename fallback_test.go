@@ -0,0 +1,97 @@
+package agify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deadAddress returns the URL of a server that has been closed
+func deadAddress() string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	return server.URL
+}
+
+func TestShouldFailOverToFallbackOnTransportError(t *testing.T) {
+	var apiKey, header string
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.URL.Query().Get("apikey")
+		header = r.Header.Get("X-Team")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer fallback.Close()
+
+	client := NewClient(WithUrl(deadAddress()), WithFallbackURL(fallback.URL), WithApiKey("secret"), WithHeader("X-Team", "search"))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Equal(t, "secret", apiKey)
+	assert.Equal(t, "search", header)
+}
+
+func TestShouldNotFailOverOnHTTPError(t *testing.T) {
+	fallbackRequests := 0
+	fallback := httptest.NewServer(countingHandler(&fallbackRequests))
+	defer fallback.Close()
+
+	primaryRequests := 0
+	primary := httptest.NewServer(failingHandler(http.StatusInternalServerError, 1, &primaryRequests))
+	defer primary.Close()
+
+	client := NewClient(WithUrl(primary.URL), WithFallbackURL(fallback.URL))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, hasStatusCode(err, http.StatusInternalServerError))
+	assert.Equal(t, 1, primaryRequests)
+	assert.Equal(t, 0, fallbackRequests)
+}
+
+func TestShouldReturnTransportErrorWithoutFallback(t *testing.T) {
+	client := NewClient(WithUrl(deadAddress()))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, isTransportError(err))
+}
+
+func TestShouldNotFailOverWhenRedirectIsRejected(t *testing.T) {
+	fallbackRequests := 0
+	fallback := httptest.NewServer(countingHandler(&fallbackRequests))
+	defer fallback.Close()
+
+	primary := redirectingServer("http://example.test")
+	defer primary.Close()
+
+	client := NewClient(WithUrl(primary.URL), WithFallbackURL(fallback.URL), WithNoRedirects())
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, errors.Is(err, ErrRedirectsDisabled))
+	assert.Equal(t, 0, fallbackRequests)
+}
+
+func TestShouldNotFailOverOnTimeout(t *testing.T) {
+	fallbackRequests := 0
+	fallback := httptest.NewServer(countingHandler(&fallbackRequests))
+	defer fallback.Close()
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer primary.Close()
+
+	client := NewClient(WithUrl(primary.URL), WithFallbackURL(fallback.URL), WithTimeout(20*time.Millisecond))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, isTransportError(err))
+	assert.Equal(t, 0, fallbackRequests)
+}
@@ -0,0 +1,63 @@
+package agify
+
+import "context"
+
+// Gender is the gender prediction for a name
+type Gender struct {
+	// Name is the name that was queried
+	Name string `json:"name"`
+	// Gender is the predicted gender
+	Gender string `json:"gender"`
+	// Probability is the probability of the predicted gender
+	Probability float64 `json:"probability"`
+	// Count is the number of people with the same name
+	Count int `json:"count"`
+}
+
+// Genderize returns the gender prediction for a name from genderize.io
+func (client *Client) Genderize(name string) (*Gender, *RateLimit, error) {
+	return client.GenderizeWithCountryContext(context.Background(), name, "")
+}
+
+// GenderizeWithCountry returns the gender prediction for a name in a country from genderize.io
+func (client *Client) GenderizeWithCountry(name string, country string) (*Gender, *RateLimit, error) {
+	return client.GenderizeWithCountryContext(context.Background(), name, country)
+}
+
+// GenderizeWithCountryContext returns the gender prediction for a name in a country from genderize.io using the provided context
+func (client *Client) GenderizeWithCountryContext(ctx context.Context, name string, country string) (*Gender, *RateLimit, error) {
+	if err := validateName(name); err != nil {
+		return nil, nil, err
+	}
+
+	url, err := parseBaseUrl(client.serviceUrls[ServiceGenderize])
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := url.Query()
+	values.Set("name", name)
+
+	if country != "" {
+		values.Set("country_id", country)
+	}
+
+	url.RawQuery = values.Encode()
+
+	body, meta, err := client.get(ctx, url.String())
+	rateLimit := meta.rateLimitOrNil()
+
+	if err != nil {
+		return nil, rateLimit, err
+	}
+
+	var gender Gender
+	err = client.decode(body, meta.contentType(), &gender)
+
+	if err != nil {
+		return nil, rateLimit, err
+	}
+
+	return &gender, rateLimit, nil
+}
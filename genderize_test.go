@@ -0,0 +1,41 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldGetGenderPrediction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "peter", r.URL.Query().Get("name"))
+		assert.Equal(t, "test-key", r.URL.Query().Get("apikey"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"count":1094417,"name":"peter","gender":"male","probability":0.99}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithGenderizeUrl(server.URL), WithApiKey("test-key"))
+	result, _, err := client.Genderize("peter")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &Gender{Name: "peter", Gender: "male", Probability: 0.99, Count: 1094417}, result)
+}
+
+func TestShouldGetGenderPredictionWithCountry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "US", r.URL.Query().Get("country_id"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"count":230,"name":"kim","gender":"female","probability":0.87}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithGenderizeUrl(server.URL))
+	result, _, err := client.GenderizeWithCountry("kim", "US")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "female", result.Gender)
+	assert.Equal(t, 0.87, result.Probability)
+}
@@ -0,0 +1,53 @@
+package agify
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// requestLimiter throttles outgoing requests with a token bucket shared by every call on the client
+type requestLimiter struct {
+	limiter *rate.Limiter
+	clock   Clock
+}
+
+// newRequestLimiter creates a request limiter, or returns nil if the rate disables it
+// A burst below 1 is raised to 1, since a token bucket without room for one token never allows a request.
+func newRequestLimiter(rps float64, burst int, clock Clock) *requestLimiter {
+	if rps <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &requestLimiter{
+		limiter: rate.NewLimiter(rate.Limit(rps), burst),
+		clock:   clock,
+	}
+}
+
+// wait blocks until a request is allowed or the context is done
+// The token is reserved and slept for through the client's clock rather than rate.Limiter.Wait, so it follows WithClock.
+func (limiter *requestLimiter) wait(ctx context.Context) error {
+	if limiter == nil {
+		return nil
+	}
+
+	now := limiter.clock.Now()
+	reservation := limiter.limiter.ReserveN(now, 1)
+
+	if !reservation.OK() {
+		return fmt.Errorf("agify: rate limiter burst %d does not allow a request", limiter.limiter.Burst())
+	}
+
+	if err := limiter.clock.Sleep(ctx, reservation.DelayFrom(now)); err != nil {
+		reservation.CancelAt(limiter.clock.Now())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package agify
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldThrottleRequestsWithRateLimiter(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithClock(clock), WithRateLimiter(2, 1))
+
+	for i := 0; i < 5; i++ {
+		_, _, err := client.Predict("michael")
+		assert.Nil(t, err)
+	}
+
+	var waited time.Duration
+
+	for _, sleep := range clock.sleeps {
+		waited += sleep
+	}
+
+	assert.Equal(t, 5, requests)
+	assert.GreaterOrEqual(t, waited, 2*time.Second)
+}
+
+func TestShouldThrottleRequestsInRealTime(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithRateLimiter(20, 1))
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, _, err := client.Predict("michael")
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, 3, requests)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestShouldStopWaitingForRateLimiterWhenCancelled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithRateLimiter(0.1, 1))
+	_, _, err := client.Predict("michael")
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err = client.PredictContext(ctx, "michael")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, requests)
+}
+
+func TestShouldTreatZeroBurstAsOne(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithClock(clock), WithRateLimiter(10, 0))
+
+	for i := 0; i < 2; i++ {
+		_, _, err := client.Predict("michael")
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, 2, requests)
+}
@@ -0,0 +1,60 @@
+package agify
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// logRequest logs a request before it is sent, with the API key redacted
+func (client *Client) logRequest(ctx context.Context, method string, url string) {
+	if client.logger == nil {
+		return
+	}
+
+	client.logger.DebugContext(ctx, "agify: request",
+		slog.String("method", method),
+		slog.String("url", redactURL(url, client.params.ApiKey)),
+	)
+}
+
+// logResponse logs the status and latency of a response
+func (client *Client) logResponse(ctx context.Context, method string, url string, meta *ResponseMeta) {
+	if client.logger == nil {
+		return
+	}
+
+	client.logger.DebugContext(ctx, "agify: response",
+		slog.String("method", method),
+		slog.String("url", redactURL(url, client.params.ApiKey)),
+		slog.Int("status", meta.StatusCode),
+		slog.Duration("latency", meta.Latency),
+	)
+}
+
+// logRetry logs a failed attempt that will be retried after the delay
+func (client *Client) logRetry(ctx context.Context, url string, attempt int, delay time.Duration, err error) {
+	if client.logger == nil {
+		return
+	}
+
+	client.logger.InfoContext(ctx, "agify: retrying request",
+		slog.String("url", redactURL(url, client.params.ApiKey)),
+		slog.Int("attempt", attempt+1),
+		slog.Duration("delay", delay),
+		slog.Any("error", err),
+	)
+}
+
+// logFailure logs a request that failed without being retried further
+func (client *Client) logFailure(ctx context.Context, url string, attempt int, err error) {
+	if client.logger == nil {
+		return
+	}
+
+	client.logger.WarnContext(ctx, "agify: request failed",
+		slog.String("url", redactURL(url, client.params.ApiKey)),
+		slog.Int("attempts", attempt+1),
+		slog.Any("error", err),
+	)
+}
@@ -0,0 +1,97 @@
+package agify
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHandler is a slog.Handler that keeps every record
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (handler *recordingHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (handler *recordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	handler.records = append(handler.records, record)
+	return nil
+}
+
+func (handler *recordingHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return handler
+}
+
+func (handler *recordingHandler) WithGroup(string) slog.Handler {
+	return handler
+}
+
+// recordAttrs returns the attributes of a record as a map
+func recordAttrs(record slog.Record) map[string]slog.Value {
+	attrs := make(map[string]slog.Value)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value
+		return true
+	})
+
+	return attrs
+}
+
+func TestShouldLogRequestAndResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	handler := &recordingHandler{}
+	client := NewClient(WithUrl(server.URL), WithApiKey("secret"), WithLogger(slog.New(handler)))
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Len(t, handler.records, 2)
+
+	request := handler.records[0]
+	assert.Equal(t, slog.LevelDebug, request.Level)
+	assert.Equal(t, "agify: request", request.Message)
+	assert.Equal(t, server.URL+"?apikey=***&name=michael", recordAttrs(request)["url"].String())
+
+	response := handler.records[1]
+	assert.Equal(t, "agify: response", response.Message)
+	assert.Equal(t, int64(http.StatusOK), recordAttrs(response)["status"].Int64())
+}
+
+func TestShouldLogRetriesAndFailures(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 3, &requests))
+	defer server.Close()
+
+	handler := &recordingHandler{}
+	client := NewClient(WithUrl(server.URL), WithRetry(1, time.Millisecond), WithLogger(slog.New(handler)))
+	_, _, err := client.Predict("michael")
+
+	assert.NotNil(t, err)
+
+	var levels []slog.Level
+
+	for _, record := range handler.records {
+		if record.Level > slog.LevelDebug {
+			levels = append(levels, record.Level)
+		}
+	}
+
+	assert.Equal(t, []slog.Level{slog.LevelInfo, slog.LevelWarn}, levels)
+}
@@ -0,0 +1,89 @@
+package agify
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// MetricsRecorder observes every request made by the client
+	MetricsRecorder interface {
+		// ObserveRequest is called after each request with its duration, the status code, and any error
+		// The status code is zero when no response was received.
+		ObserveRequest(duration time.Duration, status int, err error)
+	}
+
+	// MemoryMetrics is a MetricsRecorder that counts requests in memory
+	MemoryMetrics struct {
+		mu       sync.Mutex
+		requests int
+		errors   int
+		statuses map[int]int
+		total    time.Duration
+	}
+
+	// noopMetrics is the MetricsRecorder used when none is configured
+	noopMetrics struct{}
+)
+
+// ObserveRequest does nothing
+func (noopMetrics) ObserveRequest(time.Duration, int, error) {}
+
+// NewMemoryMetrics creates an empty in-memory metrics recorder
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		statuses: make(map[int]int),
+	}
+}
+
+// ObserveRequest counts the request, its status code, and whether it failed
+func (metrics *MemoryMetrics) ObserveRequest(duration time.Duration, status int, err error) {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	metrics.requests++
+	metrics.statuses[status]++
+	metrics.total += duration
+
+	if err != nil {
+		metrics.errors++
+	}
+}
+
+// Requests returns the number of requests observed
+func (metrics *MemoryMetrics) Requests() int {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	return metrics.requests
+}
+
+// Errors returns the number of requests that failed
+func (metrics *MemoryMetrics) Errors() int {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	return metrics.errors
+}
+
+// StatusCounts returns the number of requests observed for each status code
+func (metrics *MemoryMetrics) StatusCounts() map[int]int {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	counts := make(map[int]int, len(metrics.statuses))
+
+	for status, count := range metrics.statuses {
+		counts[status] = count
+	}
+
+	return counts
+}
+
+// TotalDuration returns the combined duration of every request observed
+func (metrics *MemoryMetrics) TotalDuration() time.Duration {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	return metrics.total
+}
@@ -0,0 +1,59 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRecorder records the status codes it observes
+type fakeRecorder struct {
+	statuses []int
+	errs     []error
+}
+
+func (recorder *fakeRecorder) ObserveRequest(duration time.Duration, status int, err error) {
+	recorder.statuses = append(recorder.statuses, status)
+	recorder.errs = append(recorder.errs, err)
+}
+
+func TestShouldObserveEachRequest(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 1, &requests))
+	defer server.Close()
+
+	recorder := &fakeRecorder{}
+	client := NewClient(WithUrl(server.URL), WithMetrics(recorder), WithRetry(1, time.Millisecond))
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{http.StatusTooManyRequests, http.StatusOK}, recorder.statuses)
+	assert.NotNil(t, recorder.errs[0])
+	assert.Nil(t, recorder.errs[1])
+}
+
+func TestShouldObserveTransportErrorWithoutStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	recorder := &fakeRecorder{}
+	client := NewClient(WithUrl(server.URL), WithMetrics(recorder))
+	client.Predict("michael")
+
+	assert.Equal(t, []int{0}, recorder.statuses)
+}
+
+func TestShouldCountRequestsInMemory(t *testing.T) {
+	metrics := NewMemoryMetrics()
+	metrics.ObserveRequest(time.Second, http.StatusOK, nil)
+	metrics.ObserveRequest(time.Second, http.StatusOK, nil)
+	metrics.ObserveRequest(time.Second, http.StatusUnauthorized, &APIError{StatusCode: http.StatusUnauthorized})
+
+	assert.Equal(t, 3, metrics.Requests())
+	assert.Equal(t, 1, metrics.Errors())
+	assert.Equal(t, map[int]int{http.StatusOK: 2, http.StatusUnauthorized: 1}, metrics.StatusCounts())
+	assert.Equal(t, 3*time.Second, metrics.TotalDuration())
+}
@@ -0,0 +1,60 @@
+package agify
+
+import (
+	"context"
+)
+
+type (
+	// CountryProbability is the probability that a name is from a country
+	CountryProbability struct {
+		// CountryID is the ISO 3166-1 alpha-2 country code
+		CountryID string `json:"country_id"`
+		// Probability is the probability the name is from the country
+		Probability float64 `json:"probability"`
+	}
+
+	// nationalizeResponse is the response from the nationalize.io API
+	nationalizeResponse struct {
+		Name    string               `json:"name"`
+		Country []CountryProbability `json:"country"`
+	}
+)
+
+// Nationalize returns the country probabilities for a name from nationalize.io
+func (client *Client) Nationalize(name string) ([]CountryProbability, *RateLimit, error) {
+	return client.NationalizeContext(context.Background(), name)
+}
+
+// NationalizeContext returns the country probabilities for a name from nationalize.io using the provided context
+func (client *Client) NationalizeContext(ctx context.Context, name string) ([]CountryProbability, *RateLimit, error) {
+	if err := validateName(name); err != nil {
+		return nil, nil, err
+	}
+
+	url, err := parseBaseUrl(client.serviceUrls[ServiceNationalize])
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := url.Query()
+	values.Set("name", name)
+
+	url.RawQuery = values.Encode()
+
+	body, meta, err := client.get(ctx, url.String())
+	rateLimit := meta.rateLimitOrNil()
+
+	if err != nil {
+		return nil, rateLimit, err
+	}
+
+	var resp nationalizeResponse
+	err = client.decode(body, meta.contentType(), &resp)
+
+	if err != nil {
+		return nil, rateLimit, err
+	}
+
+	return resp.Country, rateLimit, nil
+}
@@ -0,0 +1,45 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldGetNationalizeProbabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "michael", r.URL.Query().Get("name"))
+		assert.Equal(t, "test-key", r.URL.Query().Get("apikey"))
+		w.Header().Set("X-Rate-Limit-Remaining", "99")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","country":[{"country_id":"US","probability":0.08},{"country_id":"AU","probability":0.05},{"country_id":"NZ","probability":0.04}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl("://unused"), WithNationalizeUrl(server.URL), WithApiKey("test-key"))
+	result, rateLimit, err := client.Nationalize("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 99, rateLimit.Remaining)
+	assert.Equal(t, []CountryProbability{
+		{CountryID: "US", Probability: 0.08},
+		{CountryID: "AU", Probability: 0.05},
+		{CountryID: "NZ", Probability: 0.04},
+	}, result)
+}
+
+func TestShouldGetNationalizeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{ "error": "Invalid API key" }`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithNationalizeUrl(server.URL))
+	result, _, err := client.Nationalize("michael")
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,79 @@
+package agify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ndjsonError is the line written for a name in a failed chunk when NDJSON error lines are enabled
+type ndjsonError struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// PredictToNDJSON predicts a list of names in chunks and writes each prediction to w as a line of JSON as soon as its chunk arrives
+// A name without a predicted age is written with a null age. The writer is flushed after each chunk if it has a Flush method, such as a bufio.Writer or an http.ResponseWriter.
+// A failed chunk stops the stream and returns its error, unless WithNDJSONErrorLines is enabled, which writes an error line for each of its names and continues.
+// The returned rate limit has the lowest remaining count and farthest reset seen across the chunks.
+func (client *Client) PredictToNDJSON(ctx context.Context, names []string, w io.Writer) (*RateLimit, error) {
+	names, err := client.validateNames(names)
+
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := json.NewEncoder(w)
+	var rateLimit, chunkRateLimit *RateLimit
+
+	for _, chunk := range chunkNames(names, client.chunkSize) {
+		var predictions []Prediction
+		err := client.paceQuota(ctx, chunkRateLimit, len(chunk))
+
+		if err == nil {
+			predictions, chunkRateLimit, err = client.batchPredict(ctx, client.normalizeNameList(chunk), "")
+			rateLimit = mergeRateLimits(rateLimit, chunkRateLimit)
+		}
+
+		if err == nil {
+			predictions = client.restoreNameList(predictions, chunk)
+		}
+
+		if err != nil && (!client.ndjsonErrorLines || ctx.Err() != nil) {
+			return rateLimit, err
+		}
+
+		if err != nil {
+			for _, name := range chunk {
+				if err := encoder.Encode(ndjsonError{Name: name, Error: err.Error()}); err != nil {
+					return rateLimit, err
+				}
+			}
+		}
+
+		for _, prediction := range predictions {
+			if err := encoder.Encode(newPredictionOutput(prediction)); err != nil {
+				return rateLimit, err
+			}
+		}
+
+		if err := flushWriter(w); err != nil {
+			return rateLimit, err
+		}
+	}
+
+	return rateLimit, nil
+}
+
+// flushWriter flushes the writer if it buffers its output
+func flushWriter(w io.Writer) error {
+	switch flusher := w.(type) {
+	case interface{ Flush() error }:
+		return flusher.Flush()
+	case http.Flusher:
+		flusher.Flush()
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package agify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingChunkHandler fails the request with the given number and answers every other batch request
+func failingChunkHandler(t *testing.T, failing int) http.HandlerFunc {
+	requests := 0
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests == failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{ "error": "failed" }`))
+			return
+		}
+
+		batchHandler(t, nil)(w, r)
+	}
+}
+
+func TestShouldWritePredictionsAsNDJSON(t *testing.T) {
+	server := httptest.NewServer(batchHandler(t, nil))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(WithUrl(server.URL))
+	_, err := client.PredictToNDJSON(context.Background(), makeNames(12), &out)
+
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 12)
+
+	var prediction Prediction
+	assert.Nil(t, json.Unmarshal([]byte(lines[11]), &prediction))
+	assert.Equal(t, "name11", prediction.Name)
+}
+
+func TestShouldFlushNDJSONAfterEachChunk(t *testing.T) {
+	server := httptest.NewServer(batchHandler(t, nil))
+	defer server.Close()
+
+	var out bytes.Buffer
+	writer := bufio.NewWriterSize(&out, 64*1024)
+	client := NewClient(WithUrl(server.URL))
+	_, err := client.PredictToNDJSON(context.Background(), makeNames(12), writer)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, writer.Buffered())
+	assert.Equal(t, 12, strings.Count(out.String(), "\n"))
+}
+
+func TestShouldStopNDJSONOnFailedChunk(t *testing.T) {
+	server := httptest.NewServer(failingChunkHandler(t, 1))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(WithUrl(server.URL))
+	_, err := client.PredictToNDJSON(context.Background(), makeNames(12), &out)
+
+	assert.True(t, hasStatusCode(err, http.StatusInternalServerError))
+	assert.Empty(t, out.String())
+}
+
+func TestShouldWriteNDJSONErrorLinesForFailedChunk(t *testing.T) {
+	server := httptest.NewServer(failingChunkHandler(t, 1))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(WithUrl(server.URL), WithNDJSONErrorLines(true))
+	_, err := client.PredictToNDJSON(context.Background(), makeNames(12), &out)
+
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 12)
+
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, "name0", line["name"])
+	assert.Contains(t, line["error"], "failed")
+
+	var prediction Prediction
+	assert.Nil(t, json.Unmarshal([]byte(lines[10]), &prediction))
+	assert.Equal(t, "name10", prediction.Name)
+	assert.Nil(t, prediction.Extra)
+}
+
+func TestShouldWriteUnknownNameAsNullAgeInNDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"name":"xyzzy","age":null,"count":0},{"name":"michael","age":70,"count":875}]`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(WithUrl(server.URL))
+	_, err := client.PredictToNDJSON(context.Background(), []string{"xyzzy", "michael"}, &out)
+
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, `{"name":"xyzzy","age":null,"count":0,"country_id":""}`, lines[0])
+
+	var prediction Prediction
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &prediction))
+	assert.False(t, prediction.HasAge())
+
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &prediction))
+	assert.True(t, prediction.HasAge())
+	assert.Equal(t, 70, prediction.Age)
+}
+
+func TestShouldNotApplyChunkTimeoutToNDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		batchHandler(t, nil)(w, r)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(WithUrl(server.URL), WithChunkTimeout(time.Millisecond))
+	_, err := client.PredictToNDJSON(context.Background(), makeNames(3), &out)
+
+	assert.Nil(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(out.String()), "\n"), 3)
+}
+
+func TestShouldNormalizeNamesForNDJSON(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(WithUrl(server.URL), WithNormalizeNames(true), WithRestoreNames(true))
+	_, err := client.PredictToNDJSON(context.Background(), []string{"  Michael "}, &out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"michael"}, requested)
+	assert.Contains(t, out.String(), `"name":"Michael"`)
+}
@@ -0,0 +1,49 @@
+package agify
+
+import "strings"
+
+// normalizeName trims and lowercases the name if normalization is enabled
+func (client *Client) normalizeName(name string) string {
+	if !client.normalizeNames {
+		return name
+	}
+
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeNameList normalizes each name, returning the same slice if normalization is disabled
+func (client *Client) normalizeNameList(names []string) []string {
+	if !client.normalizeNames {
+		return names
+	}
+
+	normalized := make([]string, len(names))
+
+	for i, name := range names {
+		normalized[i] = client.normalizeName(name)
+	}
+
+	return normalized
+}
+
+// restoreName sets the prediction name to the trimmed input name if restoring is enabled
+func (client *Client) restoreName(prediction *Prediction, name string) *Prediction {
+	if client.restoreNames {
+		prediction.Name = strings.TrimSpace(name)
+	}
+
+	return prediction
+}
+
+// restoreNameList restores the name of each prediction from the input name at the same index
+func (client *Client) restoreNameList(predictions []Prediction, names []string) []Prediction {
+	if !client.restoreNames || len(predictions) != len(names) {
+		return predictions
+	}
+
+	for i := range predictions {
+		client.restoreName(&predictions[i], names[i])
+	}
+
+	return predictions
+}
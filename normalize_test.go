@@ -0,0 +1,54 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldNormalizeNameBeforeQuerying(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "michael", r.URL.Query().Get("name"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNormalizeNames(true))
+	result, _, err := client.Predict("  Michael  ")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "michael", result.Name)
+}
+
+func TestShouldRestoreInputNameCasing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNormalizeNames(true), WithRestoreNames(true))
+	result, _, err := client.Predict("  Michael  ")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Michael", result.Name)
+}
+
+func TestShouldNormalizeBatchNames(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNormalizeNames(true), WithRestoreNames(true))
+	result, _, err := client.BatchPredict([]string{" ÉMILE ", "Jane"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"émile", "jane"}, requested)
+	assert.Equal(t, "ÉMILE", result[0].Name)
+	assert.Equal(t, "Jane", result[1].Name)
+}
@@ -0,0 +1,80 @@
+package agify
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// csvHeader is the header row written by WritePredictionsCSV
+var csvHeader = []string{"name", "age", "count", "country"}
+
+// predictionOutput is a prediction as written by the output functions, with a null age for a name the API did not know
+type predictionOutput struct {
+	Name       string         `json:"name"`
+	Age        *int           `json:"age"`
+	Count      int            `json:"count"`
+	Country    string         `json:"country_id"`
+	Candidates []AgeCandidate `json:"candidates,omitempty"`
+}
+
+// newPredictionOutput copies the prediction for output, leaving the age null unless it was found
+func newPredictionOutput(prediction Prediction) predictionOutput {
+	output := predictionOutput{
+		Name:       prediction.Name,
+		Count:      prediction.Count,
+		Country:    prediction.Country,
+		Candidates: prediction.Candidates,
+	}
+
+	if prediction.Found {
+		output.Age = &prediction.Age
+	}
+
+	return output
+}
+
+// WritePredictionsJSON writes the predictions as a JSON array followed by a newline
+// A name without a predicted age is written with a null age, as the API returns it.
+func WritePredictionsJSON(w io.Writer, predictions []Prediction) error {
+	outputs := make([]predictionOutput, len(predictions))
+
+	for i, prediction := range predictions {
+		outputs[i] = newPredictionOutput(prediction)
+	}
+
+	return json.NewEncoder(w).Encode(outputs)
+}
+
+// WritePredictionsCSV writes the predictions as CSV with a name,age,count,country header
+// Fields are quoted as needed, and a name without a predicted age or a country has an empty column for it.
+func WritePredictionsCSV(w io.Writer, predictions []Prediction) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, prediction := range predictions {
+		var age string
+
+		if prediction.Found {
+			age = strconv.Itoa(prediction.Age)
+		}
+
+		record := []string{
+			prediction.Name,
+			age,
+			strconv.Itoa(prediction.Count),
+			prediction.Country,
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
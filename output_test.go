@@ -0,0 +1,64 @@
+package agify
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldWritePredictionsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WritePredictionsCSV(&buf, []Prediction{
+		{Name: "michael", Age: 70, Count: 875, Country: "US", Found: true},
+		{Name: "smith, jr", Age: 42, Count: 3, Found: true},
+		{Name: "xyzzy"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "name,age,count,country\nmichael,70,875,US\n\"smith, jr\",42,3,\nxyzzy,,0,\n", buf.String())
+}
+
+func TestShouldWritePredictionsCSVHeaderOnly(t *testing.T) {
+	var buf bytes.Buffer
+	err := WritePredictionsCSV(&buf, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "name,age,count,country\n", buf.String())
+}
+
+func TestShouldWritePredictionsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := WritePredictionsJSON(&buf, []Prediction{
+		{Name: "michael", Age: 70, Count: 875, Country: "US", Found: true},
+		{Name: "matthew", Age: 35, Count: 20, Found: true},
+		{Name: "xyzzy"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"name":"michael","age":70,"count":875,"country_id":"US"},{"name":"matthew","age":35,"count":20,"country_id":""},{"name":"xyzzy","age":null,"count":0,"country_id":""}]`+"\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, WritePredictionsJSON(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestShouldRoundTripUnknownNameThroughJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := WritePredictionsJSON(&buf, []Prediction{{Name: "xyzzy"}, {Name: "baby", Age: 0, Count: 4, Found: true}})
+	assert.Nil(t, err)
+
+	var predictions []Prediction
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &predictions))
+	assert.False(t, predictions[0].HasAge())
+	assert.True(t, predictions[1].HasAge())
+	assert.Equal(t, 0, predictions[1].Age)
+}
+
+func TestShouldEncodePredictionWithStructTags(t *testing.T) {
+	data, err := json.Marshal(Prediction{Name: "michael", Age: 30})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"michael","age":30,"count":0,"country_id":""}`, string(data))
+}
@@ -0,0 +1,19 @@
+package agify
+
+import "context"
+
+// paceQuota waits for the rate limit window to reset when the remaining quota cannot cover the next chunk, if quota aware pacing is enabled
+// The rate limit is from the previous chunk, so nothing is waited for before the first chunk or when the API did not report the remaining count.
+func (client *Client) paceQuota(ctx context.Context, rateLimit *RateLimit, names int) error {
+	if !client.quotaAwarePacing || rateLimit == nil || rateLimit.RawRemaining == "" || rateLimit.Remaining >= names {
+		return nil
+	}
+
+	wait := rateLimit.waitDuration()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	return client.clock.Sleep(ctx, wait)
+}
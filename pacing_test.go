@@ -0,0 +1,68 @@
+package agify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dwindlingHandler answers batch requests while reporting a remaining quota that drops by the names in each request
+func dwindlingHandler(t *testing.T, remaining int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		remaining -= len(r.URL.Query()["name[]"])
+
+		if remaining < 0 {
+			remaining = 25
+		}
+
+		w.Header().Set("X-Rate-Limit-Remaining", fmt.Sprint(remaining))
+		w.Header().Set("X-Rate-Reset", "30")
+		batchHandler(t, nil)(w, r)
+	}
+}
+
+func TestShouldPauseWhenRemainingQuotaRunsLow(t *testing.T) {
+	server := httptest.NewServer(dwindlingHandler(t, 25))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithClock(clock), WithQuotaAwarePacing(true))
+	result, _, err := client.BatchPredict(makeNames(40))
+
+	assert.Nil(t, err)
+	assert.Len(t, result, 40)
+	assert.Equal(t, []time.Duration{30 * time.Second}, clock.sleeps)
+}
+
+func TestShouldNotPauseWithoutQuotaAwarePacing(t *testing.T) {
+	server := httptest.NewServer(dwindlingHandler(t, 25))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithClock(clock))
+	_, _, err := client.BatchPredict(makeNames(40))
+
+	assert.Nil(t, err)
+	assert.Empty(t, clock.sleeps)
+}
+
+func TestShouldStopPacingWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(dwindlingHandler(t, 15))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	client := NewClient(WithUrl(server.URL), WithQuotaAwarePacing(true))
+	result, _, err := client.BatchPredictContext(ctx, makeNames(20))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, result, 10)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
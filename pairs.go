@@ -0,0 +1,67 @@
+package agify
+
+import "context"
+
+// NamePair is a name along with the country to predict its age in
+type NamePair struct {
+	Name    string
+	Country string
+}
+
+// BatchPredictPairs returns the age probability for each name in its own country
+// agify.io only accepts one country per batch, so the pairs are grouped by country and each group is requested as a batch.
+// The predictions follow the order of the pairs, and pairs with an empty country form their own group.
+// The returned rate limit has the lowest remaining count and farthest reset seen across the groups.
+func (client *Client) BatchPredictPairs(ctx context.Context, pairs []NamePair) ([]Prediction, *RateLimit, error) {
+	var countries []string
+	groups := make(map[string][]int)
+
+	for i, pair := range pairs {
+		if err := validateName(pair.Name); err != nil {
+			if client.skipEmpty {
+				continue
+			}
+
+			return nil, nil, err
+		}
+
+		if _, ok := groups[pair.Country]; !ok {
+			countries = append(countries, pair.Country)
+		}
+
+		groups[pair.Country] = append(groups[pair.Country], i)
+	}
+
+	results := make(map[int]Prediction, len(pairs))
+	var rateLimit *RateLimit
+
+	for _, country := range countries {
+		indexes := groups[country]
+		names := make([]string, len(indexes))
+
+		for i, index := range indexes {
+			names[i] = pairs[index].Name
+		}
+
+		predictions, groupRateLimit, err := client.BatchPredictWithCountryContext(ctx, names, country)
+		rateLimit = mergeRateLimits(rateLimit, groupRateLimit)
+
+		if err != nil {
+			return nil, rateLimit, err
+		}
+
+		for i, index := range indexes {
+			results[index] = predictions[i]
+		}
+	}
+
+	predictions := make([]Prediction, 0, len(results))
+
+	for i := range pairs {
+		if prediction, ok := results[i]; ok {
+			predictions = append(predictions, prediction)
+		}
+	}
+
+	return predictions, rateLimit, nil
+}
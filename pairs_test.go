@@ -0,0 +1,75 @@
+package agify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldGroupPairsByCountry(t *testing.T) {
+	var mu sync.Mutex
+	groups := make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		country := query.Get("country_id")
+		names := query["name[]"]
+
+		mu.Lock()
+		groups[country] = names
+		mu.Unlock()
+
+		// Respond in reverse order to check the results are aligned to the pairs
+		predictions := make([]Prediction, len(names))
+
+		for i, name := range names {
+			predictions[len(names)-1-i] = Prediction{Name: name, Age: len(name), Country: country}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(predictions)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	predictions, _, err := client.BatchPredictPairs(context.Background(), []NamePair{
+		{"michael", "US"},
+		{"jane", "GB"},
+		{"matthew", "US"},
+		{"ann", ""},
+		{"oliver", "GB"},
+	})
+
+	assert.Nil(t, err)
+
+	countries := make([]string, 0, len(groups))
+
+	for country := range groups {
+		countries = append(countries, country)
+	}
+
+	sort.Strings(countries)
+	assert.Equal(t, []string{"", "GB", "US"}, countries)
+	assert.Equal(t, []string{"michael", "matthew"}, groups["US"])
+	assert.Equal(t, []string{"jane", "oliver"}, groups["GB"])
+	assert.Equal(t, []string{"ann"}, groups[""])
+
+	assert.Len(t, predictions, 5)
+	assert.Equal(t, NamePair{"michael", "US"}, NamePair{predictions[0].Name, predictions[0].Country})
+	assert.Equal(t, NamePair{"jane", "GB"}, NamePair{predictions[1].Name, predictions[1].Country})
+	assert.Equal(t, NamePair{"matthew", "US"}, NamePair{predictions[2].Name, predictions[2].Country})
+	assert.Equal(t, NamePair{"ann", ""}, NamePair{predictions[3].Name, predictions[3].Country})
+	assert.Equal(t, NamePair{"oliver", "GB"}, NamePair{predictions[4].Name, predictions[4].Country})
+}
+
+func TestShouldRejectEmptyPairName(t *testing.T) {
+	client := NewClient()
+	_, _, err := client.BatchPredictPairs(context.Background(), []NamePair{{"michael", "US"}, {" ", "US"}})
+
+	assert.Equal(t, ErrEmptyName, err)
+}
@@ -0,0 +1,35 @@
+package agify
+
+// ParamConfig holds the query parameter names used for agify requests
+// Empty fields use the agify.io parameter names.
+type ParamConfig struct {
+	// Name is the parameter for a single name, "name" by default
+	Name string
+	// Country is the parameter for the country, "country_id" by default
+	Country string
+	// BatchName is the repeated parameter for names in a batch, "name[]" by default
+	BatchName string
+	// ApiKey is the parameter for the API key, "apikey" by default
+	ApiKey string
+}
+
+// withDefaults returns the config with empty fields set to the agify.io parameter names
+func (params ParamConfig) withDefaults() ParamConfig {
+	if params.Name == "" {
+		params.Name = "name"
+	}
+
+	if params.Country == "" {
+		params.Country = "country_id"
+	}
+
+	if params.BatchName == "" {
+		params.BatchName = "name[]"
+	}
+
+	if params.ApiKey == "" {
+		params.ApiKey = "apikey"
+	}
+
+	return params
+}
@@ -0,0 +1,39 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldUseCustomParamNames(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithApiKey("secret"), WithParamNames(ParamConfig{Name: "q", ApiKey: "key"}))
+	result, _, err := client.PredictWithCountry("michael", "US")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Equal(t, url.Values{"q": {"michael"}, "country_id": {"US"}, "key": {"secret"}}, query)
+}
+
+func TestShouldUseCustomBatchParamNames(t *testing.T) {
+	client := NewClient(WithUrl("http://example.test"), WithParamNames(ParamConfig{Country: "c", BatchName: "n"}))
+	batchUrl, err := client.BuildBatchURL([]string{"michael", "matthew"}, "US")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://example.test?c=US&n=michael&n=matthew", batchUrl)
+}
+
+func TestShouldDefaultParamNames(t *testing.T) {
+	assert.Equal(t, ParamConfig{Name: "name", Country: "country_id", BatchName: "name[]", ApiKey: "apikey"}, ParamConfig{}.withDefaults())
+}
@@ -0,0 +1,87 @@
+package agify
+
+import (
+	"context"
+	"fmt"
+)
+
+// FailedName is a name that could not be predicted and the reason why
+type FailedName struct {
+	// Name is the name that failed
+	Name string
+	// Err is the error from the request for the chunk containing the name
+	Err error
+}
+
+// IncompleteBatchError is returned with the completed predictions when a batch stops because its context is done
+// It wraps the context error, so errors.Is matches context.DeadlineExceeded or context.Canceled.
+type IncompleteBatchError struct {
+	// Failed are the names that were not predicted
+	Failed []FailedName
+	// Err is the error from the context
+	Err error
+}
+
+// Error returns the number of names that were not predicted along with the context error
+func (err *IncompleteBatchError) Error() string {
+	return fmt.Sprintf("agify: batch incomplete, %d names not predicted: %s", len(err.Failed), err.Err)
+}
+
+// Unwrap returns the error from the context
+func (err *IncompleteBatchError) Unwrap() error {
+	return err.Err
+}
+
+// BatchPredictPartial returns the predictions for the chunks that succeeded and the names from chunks that failed
+// Each chunk has its own timeout, so one slow chunk does not prevent the other chunks from returning.
+// The error is only returned when no request could be made, such as when a name is empty.
+func (client *Client) BatchPredictPartial(ctx context.Context, names []string) ([]Prediction, []FailedName, *RateLimit, error) {
+	names, err := client.validateNames(names)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var predictions []Prediction
+	var failed []FailedName
+	var rateLimit, chunkRateLimit *RateLimit
+
+	for _, chunk := range chunkNames(names, client.chunkSize) {
+		var chunkPredictions []Prediction
+		err := client.paceQuota(ctx, chunkRateLimit, len(chunk))
+
+		if err == nil {
+			chunkPredictions, chunkRateLimit, err = client.batchPredictChunk(ctx, chunk)
+			rateLimit = mergeRateLimits(rateLimit, chunkRateLimit)
+		}
+
+		if err != nil {
+			for _, name := range chunk {
+				failed = append(failed, FailedName{Name: name, Err: err})
+			}
+
+			continue
+		}
+
+		predictions = append(predictions, chunkPredictions...)
+	}
+
+	return predictions, failed, rateLimit, nil
+}
+
+// batchPredictChunk predicts a chunk of names within the chunk timeout
+func (client *Client) batchPredictChunk(ctx context.Context, chunk []string) ([]Prediction, *RateLimit, error) {
+	if client.chunkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.chunkTimeout)
+		defer cancel()
+	}
+
+	predictions, rateLimit, err := client.batchPredict(ctx, client.normalizeNameList(chunk), "")
+
+	if err != nil {
+		return nil, rateLimit, err
+	}
+
+	return client.restoreNameList(predictions, chunk), rateLimit, nil
+}
@@ -0,0 +1,54 @@
+package agify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldReturnPartialResultsWhenChunkStalls(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 2 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+
+			return
+		}
+
+		batchHandler(t, nil)(w, r)
+	}))
+	defer server.Close()
+
+	names := makeNames(25)
+	client := NewClient(WithUrl(server.URL), WithChunkTimeout(20*time.Millisecond))
+	predictions, failed, _, err := client.BatchPredictPartial(context.Background(), names)
+
+	assert.Nil(t, err)
+	assert.Len(t, predictions, 15)
+	assert.Len(t, failed, 10)
+	assert.Equal(t, names[0], predictions[0].Name)
+	assert.Equal(t, names[20], predictions[10].Name)
+	assert.Equal(t, names[10], failed[0].Name)
+	assert.True(t, errors.Is(failed[0].Err, context.DeadlineExceeded))
+}
+
+func TestShouldRejectEmptyNameInPartialBatch(t *testing.T) {
+	client := NewClient()
+	_, _, _, err := client.BatchPredictPartial(context.Background(), []string{"michael", ""})
+
+	assert.True(t, errors.Is(err, ErrEmptyName))
+}
+
+func TestShouldApplyDefaultChunkTimeout(t *testing.T) {
+	assert.Equal(t, defaultChunkTimeout, NewClient().chunkTimeout)
+	assert.Equal(t, time.Duration(0), NewClient(WithChunkTimeout(0)).chunkTimeout)
+}
@@ -0,0 +1,58 @@
+package agify
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// predictionFields are the JSON fields mapped to the Prediction struct
+var predictionFields = []string{"name", "age", "count", "country_id", "candidates"}
+
+// HasAge returns true if the API predicted an age, distinguishing an unknown name from an age of zero
+func (prediction *Prediction) HasAge() bool {
+	return prediction.Found
+}
+
+// HasCountry returns true if the prediction was localized to a country
+func (prediction *Prediction) HasCountry() bool {
+	return prediction.Country != ""
+}
+
+// UnmarshalJSON decodes the known fields and collects any unknown fields into Extra
+// Found is set when the age is present and not null, and a null country_id decodes to an empty Country.
+// Candidates are sorted by probability descending, while Age remains the age picked by the API.
+func (prediction *Prediction) UnmarshalJSON(data []byte) error {
+	// The alias type has no methods, which prevents UnmarshalJSON from recursing
+	type predictionAlias Prediction
+	var alias predictionAlias
+
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	var extra map[string]any
+
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+
+	found := extra["age"] != nil
+
+	for _, field := range predictionFields {
+		delete(extra, field)
+	}
+
+	if len(extra) == 0 {
+		extra = nil
+	}
+
+	*prediction = Prediction(alias)
+	prediction.Found = found
+	prediction.Extra = extra
+
+	sort.SliceStable(prediction.Candidates, func(i, j int) bool {
+		return prediction.Candidates[i].Probability > prediction.Candidates[j].Probability
+	})
+
+	return nil
+}
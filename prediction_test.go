@@ -0,0 +1,88 @@
+package agify
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldCollectUnknownFieldsInExtra(t *testing.T) {
+	var prediction Prediction
+	err := json.Unmarshal([]byte(`{"name":"michael","age":70,"count":875,"country_id":"US","gender":"male","probability":0.99}`), &prediction)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "michael", prediction.Name)
+	assert.Equal(t, 70, prediction.Age)
+	assert.Equal(t, 875, prediction.Count)
+	assert.Equal(t, "US", prediction.Country)
+	assert.Equal(t, map[string]any{"gender": "male", "probability": 0.99}, prediction.Extra)
+}
+
+func TestShouldLeaveExtraNilWithoutUnknownFields(t *testing.T) {
+	var predictions []Prediction
+	err := json.Unmarshal([]byte(`[{"name":"michael","age":70,"count":875}]`), &predictions)
+
+	assert.Nil(t, err)
+	assert.Len(t, predictions, 1)
+	assert.Nil(t, predictions[0].Extra)
+}
+
+func TestShouldDetectNameWithoutAge(t *testing.T) {
+	var prediction Prediction
+	err := json.Unmarshal([]byte(`{"name":"xyz","age":null,"count":0}`), &prediction)
+
+	assert.Nil(t, err)
+	assert.False(t, prediction.Found)
+	assert.False(t, prediction.HasAge())
+	assert.Equal(t, 0, prediction.Age)
+}
+
+func TestShouldDetectPredictedAgeOfZero(t *testing.T) {
+	var prediction Prediction
+	err := json.Unmarshal([]byte(`{"name":"baby","age":0,"count":12}`), &prediction)
+
+	assert.Nil(t, err)
+	assert.True(t, prediction.HasAge())
+	assert.Equal(t, 0, prediction.Age)
+}
+
+func TestShouldDetectMissingAge(t *testing.T) {
+	var prediction Prediction
+	err := json.Unmarshal([]byte(`{"name":"xyz","count":0}`), &prediction)
+
+	assert.Nil(t, err)
+	assert.False(t, prediction.HasAge())
+}
+
+func TestShouldDecodeNullCountryInBatch(t *testing.T) {
+	var predictions []Prediction
+	err := json.Unmarshal([]byte(`[{"name":"michael","age":70,"count":875,"country_id":"US"},{"name":"matthew","age":35,"count":20,"country_id":null},{"name":"jane","age":40,"count":30,"country_id":"GB"}]`), &predictions)
+
+	assert.Nil(t, err)
+	assert.Len(t, predictions, 3)
+	assert.Equal(t, "US", predictions[0].Country)
+	assert.True(t, predictions[0].HasCountry())
+	assert.Equal(t, "", predictions[1].Country)
+	assert.False(t, predictions[1].HasCountry())
+	assert.Equal(t, "GB", predictions[2].Country)
+	assert.True(t, predictions[2].HasCountry())
+}
+
+func TestShouldParseCandidatesSortedByProbability(t *testing.T) {
+	var prediction Prediction
+	err := json.Unmarshal([]byte(`{"name":"michael","age":70,"count":875,"candidates":[{"age":65,"probability":0.2},{"age":70,"probability":0.5},{"age":72,"probability":0.3}]}`), &prediction)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, prediction.Age)
+	assert.Equal(t, []AgeCandidate{{70, 0.5}, {72, 0.3}, {65, 0.2}}, prediction.Candidates)
+	assert.Nil(t, prediction.Extra)
+}
+
+func TestShouldLeaveCandidatesNilWhenMissing(t *testing.T) {
+	var prediction Prediction
+	err := json.Unmarshal([]byte(`{"name":"michael","age":70,"count":875}`), &prediction)
+
+	assert.Nil(t, err)
+	assert.Nil(t, prediction.Candidates)
+}
@@ -0,0 +1,136 @@
+package agify
+
+import "sort"
+
+// PredictionSet is a list of predictions, such as the results of a batch, with summary helpers
+// Predictions without an age are ignored by the age helpers.
+type PredictionSet []Prediction
+
+// NewPredictionSet wraps the predictions in a PredictionSet
+func NewPredictionSet(predictions []Prediction) PredictionSet {
+	return PredictionSet(predictions)
+}
+
+// AverageAge returns the mean predicted age, or zero if no prediction has an age
+func (set PredictionSet) AverageAge() float64 {
+	ages := set.ages()
+
+	if len(ages) == 0 {
+		return 0
+	}
+
+	total := 0
+
+	for _, age := range ages {
+		total += age
+	}
+
+	return float64(total) / float64(len(ages))
+}
+
+// MedianAge returns the median predicted age, or zero if no prediction has an age
+// With an even number of ages the mean of the middle two is rounded down.
+func (set PredictionSet) MedianAge() int {
+	ages := set.ages()
+
+	if len(ages) == 0 {
+		return 0
+	}
+
+	sort.Ints(ages)
+	middle := len(ages) / 2
+
+	if len(ages)%2 == 0 {
+		return (ages[middle-1] + ages[middle]) / 2
+	}
+
+	return ages[middle]
+}
+
+// TotalCount returns the sum of the data points behind every prediction
+func (set PredictionSet) TotalCount() int {
+	total := 0
+
+	for _, prediction := range set {
+		total += prediction.Count
+	}
+
+	return total
+}
+
+// ByCountry groups the predictions by country, with predictions without a country under an empty key
+func (set PredictionSet) ByCountry() map[string][]Prediction {
+	groups := make(map[string][]Prediction)
+
+	for _, prediction := range set {
+		groups[prediction.Country] = append(groups[prediction.Country], prediction)
+	}
+
+	return groups
+}
+
+// AgeHistogram returns the number of predictions at each predicted age
+func (set PredictionSet) AgeHistogram() map[int]int {
+	return set.AgeHistogramBuckets(1)
+}
+
+// AgeHistogramBuckets returns the number of predictions in each bucket of width ages, keyed by the first age of the bucket
+// A width below 1 is treated as 1, so each age has its own bucket.
+func (set PredictionSet) AgeHistogramBuckets(width int) map[int]int {
+	if width < 1 {
+		width = 1
+	}
+
+	histogram := make(map[int]int)
+
+	for _, age := range set.ages() {
+		histogram[age/width*width]++
+	}
+
+	return histogram
+}
+
+// Oldest returns the prediction with the highest age, or nil if no prediction has an age
+func (set PredictionSet) Oldest() *Prediction {
+	return set.find(func(candidate, current *Prediction) bool {
+		return candidate.Age > current.Age
+	})
+}
+
+// Youngest returns the prediction with the lowest age, or nil if no prediction has an age
+func (set PredictionSet) Youngest() *Prediction {
+	return set.find(func(candidate, current *Prediction) bool {
+		return candidate.Age < current.Age
+	})
+}
+
+// ages returns the ages of the predictions that have one
+func (set PredictionSet) ages() []int {
+	var ages []int
+
+	for i := range set {
+		if set[i].HasAge() {
+			ages = append(ages, set[i].Age)
+		}
+	}
+
+	return ages
+}
+
+// find returns a copy of the best prediction with an age, keeping the first on ties
+func (set PredictionSet) find(better func(candidate, current *Prediction) bool) *Prediction {
+	var found *Prediction
+
+	for i := range set {
+		if set[i].HasAge() && (found == nil || better(&set[i], found)) {
+			found = &set[i]
+		}
+	}
+
+	if found == nil {
+		return nil
+	}
+
+	prediction := *found
+	return &prediction
+}
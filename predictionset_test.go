@@ -0,0 +1,68 @@
+package agify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldSummarizePredictionSet(t *testing.T) {
+	set := NewPredictionSet([]Prediction{
+		{Name: "michael", Age: 70, Count: 875, Country: "US", Found: true},
+		{Name: "matthew", Age: 35, Count: 20, Country: "US", Found: true},
+		{Name: "jane", Age: 40, Count: 30, Country: "GB", Found: true},
+		{Name: "xyz", Count: 0},
+	})
+
+	assert.InDelta(t, 48.333, set.AverageAge(), 0.001)
+	assert.Equal(t, 40, set.MedianAge())
+	assert.Equal(t, 925, set.TotalCount())
+	assert.Equal(t, "michael", set.Oldest().Name)
+	assert.Equal(t, "matthew", set.Youngest().Name)
+
+	byCountry := set.ByCountry()
+	assert.Len(t, byCountry["US"], 2)
+	assert.Len(t, byCountry["GB"], 1)
+	assert.Len(t, byCountry[""], 1)
+}
+
+func TestShouldReturnMedianForEvenCount(t *testing.T) {
+	set := NewPredictionSet([]Prediction{
+		{Age: 30, Found: true},
+		{Age: 10, Found: true},
+		{Age: 25, Found: true},
+		{Age: 40, Found: true},
+	})
+
+	assert.Equal(t, 27, set.MedianAge())
+	assert.Equal(t, 26.25, set.AverageAge())
+}
+
+func TestShouldReturnZeroValuesForEmptySet(t *testing.T) {
+	set := NewPredictionSet(nil)
+
+	assert.Equal(t, 0.0, set.AverageAge())
+	assert.Equal(t, 0, set.MedianAge())
+	assert.Equal(t, 0, set.TotalCount())
+	assert.Empty(t, set.ByCountry())
+	assert.Nil(t, set.Oldest())
+	assert.Nil(t, set.Youngest())
+	assert.Equal(t, map[int]int{}, set.AgeHistogram())
+	assert.Equal(t, map[int]int{}, set.AgeHistogramBuckets(10))
+}
+
+func TestShouldCountPredictionsByAge(t *testing.T) {
+	set := NewPredictionSet([]Prediction{
+		{Age: 9, Found: true},
+		{Age: 10, Found: true},
+		{Age: 19, Found: true},
+		{Age: 19, Found: true},
+		{Age: 35, Found: true},
+		{Age: 70, Found: true},
+		{Name: "unknown"},
+	})
+
+	assert.Equal(t, map[int]int{9: 1, 10: 1, 19: 2, 35: 1, 70: 1}, set.AgeHistogram())
+	assert.Equal(t, map[int]int{0: 1, 10: 3, 30: 1, 70: 1}, set.AgeHistogramBuckets(10))
+	assert.Equal(t, set.AgeHistogram(), set.AgeHistogramBuckets(0))
+}
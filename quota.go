@@ -0,0 +1,27 @@
+package agify
+
+import "context"
+
+// quotaName is the name used to read the rate limit headers
+const quotaName = "michael"
+
+// QuotaStatus returns the current rate limit without returning a prediction
+// agify.io only reports the rate limit on predictions, so this consumes one unit of quota. The cache is bypassed.
+func (client *Client) QuotaStatus(ctx context.Context) (*RateLimit, error) {
+	ctx, span := client.startSpan(ctx, "agify.QuotaStatus", 1, "")
+	_, meta, err := client.requestPrediction(ctx, quotaName, "")
+	endSpan(span, err)
+
+	return meta.rateLimitOrNil(), err
+}
+
+// Ping checks that the API is reachable and the API key is accepted by predicting a well-known name
+// It returns an APIError when the API rejects the request, such as a 401 for an invalid key, or a transport error when it is unreachable.
+// The cache is bypassed, while the configured timeout and retries apply as they do to any request.
+func (client *Client) Ping(ctx context.Context) error {
+	ctx, span := client.startSpan(ctx, "agify.Ping", 1, "")
+	_, _, err := client.requestPrediction(ctx, quotaName, "")
+	endSpan(span, err)
+
+	return err
+}
@@ -0,0 +1,98 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldReturnQuotaStatus(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Rate-Limit-Limit", "1000")
+		w.Header().Set("X-Rate-Limit-Remaining", "728")
+		w.Header().Set("X-Rate-Reset", "15281")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(time.Minute))
+	client.Predict("michael")
+
+	rateLimit, err := client.QuotaStatus(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, rateLimit.Limit)
+	assert.Equal(t, 728, rateLimit.Remaining)
+	assert.Equal(t, 15281*time.Second, rateLimit.Reset)
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldReturnQuotaStatusWhenRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{ "error": "Request limit reached" }`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	rateLimit, err := client.QuotaStatus(context.Background())
+
+	assert.NotNil(t, err)
+	assert.True(t, rateLimit.IsExhausted())
+}
+
+func TestShouldPing(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(time.Minute))
+
+	assert.Nil(t, client.Ping(context.Background()))
+	assert.Nil(t, client.Ping(context.Background()))
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldNotCacheProbePredictions(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(time.Minute))
+
+	assert.Nil(t, client.Ping(context.Background()))
+	_, err := client.QuotaStatus(context.Background())
+	assert.Nil(t, err)
+
+	_, _, err = client.Predict(quotaName)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, requests)
+}
+
+func TestShouldPingWithInvalidApiKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{ "error": "Invalid API key" }`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithApiKey("bad"))
+	err := client.Ping(context.Background())
+
+	assert.True(t, hasStatusCode(err, http.StatusUnauthorized))
+}
+
+func TestShouldPingUnreachableHost(t *testing.T) {
+	client := NewClient(WithUrl(deadAddress()))
+	err := client.Ping(context.Background())
+
+	assert.True(t, isTransportError(err))
+}
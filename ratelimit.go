@@ -0,0 +1,180 @@
+package agify
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit is the rate limiting information from the API
+// Client methods return a nil RateLimit when the response had no rate limiting headers or no request was made, so check for nil before reading the fields.
+type RateLimit struct {
+	// Limit is the number of names allowed in the current window
+	Limit int
+	// Remaining is the number of names left in the current window
+	Remaining int
+	// Reset is the time until the current window resets
+	Reset time.Duration
+	// RetryAfter is the time the API asked the client to wait before retrying
+	RetryAfter time.Duration
+
+	// RawLimit is the unparsed X-Rate-Limit-Limit or RateLimit-Limit header
+	RawLimit string
+	// RawRemaining is the unparsed X-Rate-Limit-Remaining or RateLimit-Remaining header
+	RawRemaining string
+	// RawReset is the unparsed X-Rate-Reset, X-Rate-Limit-Reset or RateLimit-Reset header
+	RawReset string
+	// RawRetryAfter is the unparsed Retry-After header
+	RawRetryAfter string
+}
+
+// IsExhausted returns true when there are no requests remaining in the current window
+func (rateLimit *RateLimit) IsExhausted() bool {
+	return rateLimit != nil && rateLimit.Remaining == 0
+}
+
+// TimeUntilReset returns the time until the current window resets, or zero if it is unknown
+func (rateLimit *RateLimit) TimeUntilReset() time.Duration {
+	if rateLimit == nil {
+		return 0
+	}
+
+	return rateLimit.Reset
+}
+
+// ResetAt returns the instant the current window resets relative to now
+func (rateLimit *RateLimit) ResetAt(now time.Time) time.Time {
+	return now.Add(rateLimit.TimeUntilReset())
+}
+
+// waitDuration returns how long the API asked the client to wait, preferring Retry-After over the reset
+func (rateLimit *RateLimit) waitDuration() time.Duration {
+	if rateLimit == nil {
+		return 0
+	}
+
+	if rateLimit.RetryAfter > 0 {
+		return rateLimit.RetryAfter
+	}
+
+	return rateLimit.Reset
+}
+
+// mergeRateLimits combines rate limits from several responses into a conservative view
+// The lowest remaining count and the farthest reset are kept, ignoring headers that were not sent.
+func mergeRateLimits(a *RateLimit, b *RateLimit) *RateLimit {
+	if a == nil {
+		return b
+	}
+
+	if b == nil {
+		return a
+	}
+
+	merged := *b
+
+	if a.RawRemaining != "" && (b.RawRemaining == "" || a.Remaining < b.Remaining) {
+		merged.Remaining = a.Remaining
+		merged.RawRemaining = a.RawRemaining
+	}
+
+	if a.Reset > b.Reset {
+		merged.Reset = a.Reset
+		merged.RawReset = a.RawReset
+	}
+
+	if a.RetryAfter > b.RetryAfter {
+		merged.RetryAfter = a.RetryAfter
+		merged.RawRetryAfter = a.RawRetryAfter
+	}
+
+	return &merged
+}
+
+var (
+	// limitHeaders are the candidate headers for the request limit, in order of preference
+	limitHeaders = []string{"X-Rate-Limit-Limit", "RateLimit-Limit"}
+
+	// remainingHeaders are the candidate headers for the remaining requests, in order of preference
+	remainingHeaders = []string{"X-Rate-Limit-Remaining", "RateLimit-Remaining"}
+
+	// resetHeaders are the candidate headers for the seconds until the window resets, in order of preference
+	resetHeaders = []string{"X-Rate-Reset", "X-Rate-Limit-Reset", "RateLimit-Reset"}
+)
+
+// parseRateLimit reads the rate limiting headers from a response received at now
+// Both the agify.io X-Rate-* headers and the IETF draft RateLimit-* headers are read, preferring the agify.io headers.
+// It returns nil when the response has none of the headers, and malformed or missing headers otherwise leave the numeric fields at zero.
+func parseRateLimit(header http.Header, now time.Time) *RateLimit {
+	rateLimit := &RateLimit{
+		RawLimit:      firstHeader(header, limitHeaders),
+		RawRemaining:  firstHeader(header, remainingHeaders),
+		RawReset:      firstHeader(header, resetHeaders),
+		RawRetryAfter: header.Get("Retry-After"),
+	}
+
+	if rateLimit.RawLimit == "" && rateLimit.RawRemaining == "" && rateLimit.RawReset == "" && rateLimit.RawRetryAfter == "" {
+		return nil
+	}
+
+	rateLimit.Limit = parseHeaderInt(rateLimit.RawLimit)
+	rateLimit.Remaining = parseHeaderInt(rateLimit.RawRemaining)
+	rateLimit.Reset = parseHeaderSeconds(rateLimit.RawReset)
+	rateLimit.RetryAfter = parseRetryAfter(rateLimit.RawRetryAfter, now)
+
+	return rateLimit
+}
+
+// firstHeader returns the value of the first candidate header that is present
+// Header names are matched case-insensitively because http.Header canonicalizes them.
+func firstHeader(header http.Header, names []string) string {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// parseHeaderInt parses a header value as an integer, returning zero if it is not valid
+func parseHeaderInt(value string) int {
+	i, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
+
+// parseHeaderSeconds parses a header value as a number of seconds, allowing fractions, returning zero if it is not valid
+func parseHeaderSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// parseRetryAfter parses a Retry-After header in either the seconds or HTTP-date form
+// Missing, malformed, or past values return zero.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+
+	if err != nil || !date.After(now) {
+		return 0
+	}
+
+	return date.Sub(now)
+}
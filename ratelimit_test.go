@@ -0,0 +1,153 @@
+package agify
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldParseRateLimitHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Rate-Limit-Limit", "1000")
+	header.Set("X-Rate-Limit-Remaining", "0")
+	header.Set("X-Rate-Reset", "60")
+
+	rateLimit := parseRateLimit(header, time.Now())
+
+	assert.Equal(t, 1000, rateLimit.Limit)
+	assert.Equal(t, 0, rateLimit.Remaining)
+	assert.Equal(t, time.Minute, rateLimit.Reset)
+	assert.Equal(t, "1000", rateLimit.RawLimit)
+	assert.True(t, rateLimit.IsExhausted())
+}
+
+func TestShouldTolerateMalformedRateLimitHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Rate-Limit-Limit", "lots")
+	header.Set("X-Rate-Limit-Remaining", "12.5")
+	header.Set("X-Rate-Reset", "soon")
+
+	rateLimit := parseRateLimit(header, time.Now())
+
+	assert.Equal(t, 0, rateLimit.Limit)
+	assert.Equal(t, 0, rateLimit.Remaining)
+	assert.Equal(t, time.Duration(0), rateLimit.Reset)
+	assert.Equal(t, "lots", rateLimit.RawLimit)
+	assert.Equal(t, "12.5", rateLimit.RawRemaining)
+	assert.Equal(t, "soon", rateLimit.RawReset)
+}
+
+func TestShouldTolerateMissingRateLimitHeaders(t *testing.T) {
+	rateLimit := parseRateLimit(http.Header{}, time.Now())
+
+	assert.Nil(t, rateLimit)
+	assert.False(t, rateLimit.IsExhausted())
+	assert.Equal(t, time.Duration(0), rateLimit.TimeUntilReset())
+}
+
+func TestShouldNotBeExhaustedWithRemainingRequests(t *testing.T) {
+	rateLimit := &RateLimit{Remaining: 1}
+	assert.False(t, rateLimit.IsExhausted())
+}
+
+func TestShouldParseRetryAfterSeconds(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "120")
+
+	rateLimit := parseRateLimit(header, time.Now())
+
+	assert.Equal(t, 2*time.Minute, rateLimit.RetryAfter)
+	assert.Equal(t, "120", rateLimit.RawRetryAfter)
+}
+
+func TestShouldParseRetryAfterDate(t *testing.T) {
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	value := now.Add(90 * time.Second).Format(http.TimeFormat)
+
+	assert.Equal(t, 90*time.Second, parseRetryAfter(value, now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("later", now))
+}
+
+func TestShouldReturnTimeUntilReset(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Rate-Reset", "15281")
+
+	assert.Equal(t, 15281*time.Second, parseRateLimit(header, time.Now()).TimeUntilReset())
+	assert.Equal(t, time.Duration(0), parseRateLimit(http.Header{}, time.Now()).TimeUntilReset())
+
+	var rateLimit *RateLimit
+	assert.Equal(t, time.Duration(0), rateLimit.TimeUntilReset())
+}
+
+func TestShouldReturnResetAt(t *testing.T) {
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	header := http.Header{}
+	header.Set("X-Rate-Reset", "3600")
+
+	assert.Equal(t, now.Add(time.Hour), parseRateLimit(header, time.Now()).ResetAt(now))
+}
+
+func TestShouldParseFractionalReset(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Rate-Reset", "0.5")
+
+	assert.Equal(t, 500*time.Millisecond, parseRateLimit(header, time.Now()).Reset)
+}
+
+func TestShouldMergeRateLimits(t *testing.T) {
+	a := &RateLimit{Limit: 1000, Remaining: 10, RawRemaining: "10", Reset: time.Minute}
+	b := &RateLimit{Limit: 1000, Remaining: 20, RawRemaining: "20", Reset: time.Hour}
+
+	merged := mergeRateLimits(a, b)
+
+	assert.Equal(t, 1000, merged.Limit)
+	assert.Equal(t, 10, merged.Remaining)
+	assert.Equal(t, time.Hour, merged.Reset)
+	assert.Equal(t, 20, b.Remaining)
+}
+
+func TestShouldMergeNilRateLimits(t *testing.T) {
+	rateLimit := &RateLimit{Remaining: 10}
+
+	assert.Nil(t, mergeRateLimits(nil, nil))
+	assert.Same(t, rateLimit, mergeRateLimits(nil, rateLimit))
+	assert.Same(t, rateLimit, mergeRateLimits(rateLimit, nil))
+}
+
+func TestShouldIgnoreMissingRemainingWhenMerging(t *testing.T) {
+	a := &RateLimit{}
+	b := &RateLimit{Remaining: 20, RawRemaining: "20"}
+
+	assert.Equal(t, 20, mergeRateLimits(a, b).Remaining)
+	assert.Equal(t, 20, mergeRateLimits(b, a).Remaining)
+}
+
+func TestShouldParseIETFRateLimitHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("RateLimit-Limit", "100")
+	header.Set("ratelimit-remaining", "42")
+	header.Set("RATELIMIT-RESET", "30")
+
+	rateLimit := parseRateLimit(header, time.Now())
+
+	assert.Equal(t, 100, rateLimit.Limit)
+	assert.Equal(t, 42, rateLimit.Remaining)
+	assert.Equal(t, 30*time.Second, rateLimit.Reset)
+	assert.Equal(t, "42", rateLimit.RawRemaining)
+}
+
+func TestShouldPreferAgifyRateLimitHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Rate-Limit-Remaining", "7")
+	header.Set("RateLimit-Remaining", "42")
+	header.Set("X-Rate-Limit-Reset", "15")
+	header.Set("RateLimit-Reset", "30")
+
+	rateLimit := parseRateLimit(header, time.Now())
+
+	assert.Equal(t, 7, rateLimit.Remaining)
+	assert.Equal(t, 15*time.Second, rateLimit.Reset)
+}
@@ -0,0 +1,31 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+)
+
+// PredictRaw returns the age probability for a name along with the HTTP response it was decoded from
+// The response body has already been consumed, so it is replaced with a reader over the bytes that were read.
+// The cache is bypassed so there is always a response, and it is also returned with API errors when one was received.
+func (client *Client) PredictRaw(ctx context.Context, name string) (*Prediction, *http.Response, error) {
+	if err := validateName(name); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, span := client.startSpan(ctx, "agify.Predict", 1, "")
+	prediction, meta, err := client.requestPrediction(ctx, client.normalizeName(name), "")
+	endSpan(span, err)
+
+	var resp *http.Response
+
+	if meta != nil {
+		resp = meta.response
+	}
+
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return client.restoreName(prediction, name), resp, nil
+}
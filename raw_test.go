@@ -0,0 +1,63 @@
+package agify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldReturnRawResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Custom", "value")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	prediction, resp, err := client.PredictRaw(context.Background(), "michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, prediction.Age)
+	assert.Equal(t, "value", resp.Header.Get("X-Custom"))
+
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"michael","age":70,"count":875}`, string(body))
+}
+
+func TestShouldReturnRawResponseWithAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Custom", "value")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{ "error": "Invalid API key" }`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	prediction, resp, err := client.PredictRaw(context.Background(), "michael")
+
+	assert.Nil(t, prediction)
+	assert.True(t, hasStatusCode(err, http.StatusUnauthorized))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "value", resp.Header.Get("X-Custom"))
+}
+
+func TestShouldNotCacheRawPrediction(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithCache(time.Minute))
+	_, _, err := client.PredictRaw(context.Background(), "michael")
+	assert.Nil(t, err)
+
+	_, _, err = client.Predict("michael")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, requests)
+}
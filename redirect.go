@@ -0,0 +1,56 @@
+package agify
+
+import (
+	"errors"
+	"net/http"
+)
+
+// maxRedirects matches the number of redirects followed by the default http client policy
+const maxRedirects = 10
+
+// withRedirectPolicy returns a copy of the http client that removes the API key from cross-host redirects
+// The configured policy is used if set, otherwise the policy of the http client, otherwise the default limit of redirects.
+func (defaults *clientDefaults) withRedirectPolicy(httpClient *http.Client) *http.Client {
+	policy := defaults.redirectPolicy
+
+	if policy == nil {
+		policy = httpClient.CheckRedirect
+	}
+
+	if policy == nil {
+		policy = defaultRedirectPolicy
+	}
+
+	clone := *httpClient
+	clone.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		stripCrossHostApiKey(req, via, defaults.params.ApiKey)
+		return policy(req, via)
+	}
+
+	return &clone
+}
+
+// defaultRedirectPolicy stops after maxRedirects like the default http client
+func defaultRedirectPolicy(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	return nil
+}
+
+// stripCrossHostApiKey removes the API key query parameter when a redirect leaves the original host
+func stripCrossHostApiKey(req *http.Request, via []*http.Request, param string) {
+	if len(via) == 0 || req.URL.Host == via[0].URL.Host {
+		return
+	}
+
+	values := req.URL.Query()
+
+	if !values.Has(param) {
+		return
+	}
+
+	values.Del(param)
+	req.URL.RawQuery = values.Encode()
+}
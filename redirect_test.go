@@ -0,0 +1,89 @@
+package agify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// redirectingServer redirects every request to the target, keeping the query string
+func redirectingServer(target string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target+"/?"+r.URL.RawQuery, http.StatusFound)
+	}))
+}
+
+func TestShouldStripApiKeyOnCrossHostRedirect(t *testing.T) {
+	var apiKey string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.URL.Query().Get("apikey")
+		assert.Equal(t, "michael", r.URL.Query().Get("name"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer target.Close()
+
+	// localhost and 127.0.0.1 are different hosts for the same server
+	targetUrl, _ := url.Parse(target.URL)
+	server := redirectingServer("http://localhost:" + targetUrl.Port())
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithApiKey("secret"))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Equal(t, "", apiKey)
+}
+
+func TestShouldKeepApiKeyOnSameHostRedirect(t *testing.T) {
+	var apiKey string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new?"+r.URL.RawQuery, http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.URL.Query().Get("apikey")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL+"/old"), WithApiKey("secret"))
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", apiKey)
+}
+
+func TestShouldRejectRedirectsWhenDisabled(t *testing.T) {
+	server := redirectingServer("http://example.test")
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNoRedirects())
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, errors.Is(err, ErrRedirectsDisabled))
+}
+
+func TestShouldUseCustomRedirectPolicy(t *testing.T) {
+	server := redirectingServer("http://example.test")
+	defer server.Close()
+
+	var redirected string
+	policyErr := errors.New("blocked")
+	client := NewClient(WithUrl(server.URL), WithApiKey("secret"), WithRedirectPolicy(func(req *http.Request, via []*http.Request) error {
+		redirected = req.URL.String()
+		return policyErr
+	}))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, errors.Is(err, policyErr))
+	assert.NotContains(t, redirected, "secret")
+	assert.Contains(t, redirected, "example.test")
+}
@@ -0,0 +1,101 @@
+package agify
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryPolicy decides whether to retry a request and how long to wait first
+// The response is nil when the request failed without a response, otherwise its body is buffered and can be read.
+type RetryPolicy func(resp *http.Response, err error, attempt int) (retry bool, delay time.Duration)
+
+// retryDecision returns whether to retry the attempt and the delay, using the retry policy if one is set
+func (client *Client) retryDecision(meta *ResponseMeta, err error, attempt int) (bool, time.Duration) {
+	if client.retryPolicy == nil {
+		if err == nil || attempt >= client.maxRetries || !client.shouldRetry(err) {
+			return false, 0
+		}
+
+		return true, client.retryDelay(attempt, meta.rateLimitOrNil(), hasStatusCode(err, http.StatusTooManyRequests))
+	}
+
+	var resp *http.Response
+
+	if meta != nil {
+		resp = meta.response
+	}
+
+	retry, delay := client.retryPolicy(resp, err, attempt)
+
+	// The policy may have read the body, so it is restored for anything that uses the response later
+	if resp != nil {
+		resp.Body = io.NopCloser(bytes.NewReader(meta.body))
+	}
+
+	return retry, delay
+}
+
+// shouldRetry returns true if the error is worth retrying
+func (client *Client) shouldRetry(err error) bool {
+	var apiErr *APIError
+
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	if apiErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return client.retryServerErrors && apiErr.StatusCode >= http.StatusInternalServerError
+}
+
+// retryDelay returns how long to wait before the next attempt
+// The Retry-After header is preferred, then the rate limit reset when the request was rate limited,
+// otherwise the base delay is doubled for each attempt with jitter added.
+func (client *Client) retryDelay(attempt int, rateLimit *RateLimit, limited bool) time.Duration {
+	if rateLimit != nil && rateLimit.RetryAfter > 0 {
+		return rateLimit.RetryAfter
+	}
+
+	// The reset is when the quota window ends, which is only worth waiting for when the quota ran out
+	if wait := rateLimit.waitDuration(); limited && wait > 0 {
+		return wait
+	}
+
+	delay := client.retryBaseDelay << attempt
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(client.jitter.int63n(int64(delay/2)+1))
+}
+
+// jitterSource is a concurrency-safe source of random jitter
+type jitterSource struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// newJitterSource wraps the source, seeding a new one from the current time if it is nil
+func newJitterSource(source rand.Source) *jitterSource {
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+
+	return &jitterSource{rand: rand.New(source)}
+}
+
+// int63n returns a random number in [0, n)
+func (jitter *jitterSource) int63n(n int64) int64 {
+	jitter.mu.Lock()
+	defer jitter.mu.Unlock()
+
+	return jitter.rand.Int63n(n)
+}
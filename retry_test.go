@@ -0,0 +1,315 @@
+package agify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingHandler fails with the status code the given number of times before succeeding
+func failingHandler(status int, failures int, requests *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+
+		if *requests <= failures {
+			w.WriteHeader(status)
+			w.Write([]byte(`{ "error": "failed" }`))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}
+}
+
+func TestShouldRetryTooManyRequests(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 2, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithRetry(3, time.Millisecond))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Equal(t, 3, requests)
+}
+
+func TestShouldReturnFinalErrorWhenRetriesExhausted(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 5, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithRetry(2, time.Millisecond))
+	result, _, err := client.Predict("michael")
+
+	var apiErr *APIError
+	assert.Nil(t, result)
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, 3, requests)
+}
+
+func TestShouldNotRetryServerErrorsByDefault(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusInternalServerError, 1, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithRetry(3, time.Millisecond))
+	_, _, err := client.Predict("michael")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestShouldRetryServerErrorsWhenEnabled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusServiceUnavailable, 1, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithRetry(3, time.Millisecond), WithRetryServerErrors(true))
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldStopRetryingWhenContextExpires(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 5, &requests))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := NewClient(WithUrl(server.URL), WithRetry(5, time.Second))
+	_, _, err := client.PredictContext(ctx, "michael")
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 1, requests)
+}
+
+func TestShouldPreferRateLimitResetForRetryDelay(t *testing.T) {
+	client := NewClient(WithRetry(3, time.Millisecond))
+	delay := client.retryDelay(0, &RateLimit{Reset: 2 * time.Second}, true)
+	assert.Equal(t, 2*time.Second, delay)
+}
+
+func TestShouldIgnoreRateLimitResetForServerErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Rate-Limit-Remaining", "900")
+		w.Header().Set("X-Rate-Reset", "15281")
+
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{ "error": "Internal server error" }`))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithRetry(3, 100*time.Millisecond), WithRetryServerErrors(true), WithClock(clock), WithJitterSource(zeroSource{}))
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond}, clock.sleeps)
+}
+
+func TestShouldHonorRetryAfterForServerErrors(t *testing.T) {
+	client := NewClient(WithRetry(3, time.Millisecond))
+	delay := client.retryDelay(0, &RateLimit{Reset: time.Hour, RetryAfter: 3 * time.Second}, false)
+	assert.Equal(t, 3*time.Second, delay)
+}
+
+func TestShouldBackoffExponentially(t *testing.T) {
+	client := NewClient(WithRetry(3, 100*time.Millisecond))
+
+	for attempt := 0; attempt < 3; attempt++ {
+		delay := client.retryDelay(attempt, nil, false)
+		max := 100 * time.Millisecond << attempt
+		assert.GreaterOrEqual(t, delay, max/2)
+		assert.LessOrEqual(t, delay, max)
+	}
+}
+
+func TestShouldPreferRetryAfterOverRateLimitReset(t *testing.T) {
+	client := NewClient(WithRetry(3, time.Millisecond))
+	delay := client.retryDelay(0, &RateLimit{Reset: 2 * time.Second, RetryAfter: time.Second}, true)
+	assert.Equal(t, time.Second, delay)
+}
+
+func TestShouldWaitForRateLimitReset(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests == 1 {
+			w.Header().Set("X-Rate-Reset", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{ "error": "Request limit reached" }`))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	client := NewClient(WithUrl(server.URL), WithRateLimitWait(true))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Equal(t, 2, requests)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestShouldStopWaitingForRateLimitWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Reset", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{ "error": "Request limit reached" }`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := NewClient(WithUrl(server.URL), WithRateLimitWait(true))
+	_, _, err := client.PredictContext(ctx, "michael")
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestShouldNotWaitForRateLimitByDefault(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 1, &requests))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	_, _, err := client.Predict("michael")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+// zeroSource is a rand.Source that always returns zero, so jitter is always at its minimum
+type zeroSource struct{}
+
+func (zeroSource) Int63() int64 {
+	return 0
+}
+
+func (zeroSource) Seed(int64) {}
+
+func TestShouldFollowBackoffScheduleWithFixedJitter(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 4, &requests))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithRetry(3, 100*time.Millisecond), WithClock(clock), WithJitterSource(zeroSource{}))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, hasStatusCode(err, http.StatusTooManyRequests))
+	assert.Equal(t, 4, requests)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond}, clock.sleeps)
+}
+
+func TestShouldRepeatBackoffWithSameSeed(t *testing.T) {
+	first := NewClient(WithRetry(5, time.Second), WithJitterSource(rand.NewSource(42)))
+	second := NewClient(WithRetry(5, time.Second), WithJitterSource(rand.NewSource(42)))
+
+	for attempt := 0; attempt < 5; attempt++ {
+		assert.Equal(t, first.retryDelay(attempt, nil, false), second.retryDelay(attempt, nil, false))
+	}
+}
+
+func TestShouldRetryWithCustomPolicyOnBody(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests <= 2 {
+			w.Write([]byte(`{ "error": "throttled" }`))
+			return
+		}
+
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	policy := func(resp *http.Response, err error, attempt int) (bool, time.Duration) {
+		if resp == nil || attempt >= 5 {
+			return false, 0
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		return strings.Contains(string(body), "throttled"), time.Second
+	}
+
+	client := NewClient(WithUrl(server.URL), WithClock(clock), WithRetryPolicy(policy))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, clock.sleeps)
+}
+
+func TestShouldNotRetryWhenCustomPolicyDeclines(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(failingHandler(http.StatusTooManyRequests, 5, &requests))
+	defer server.Close()
+
+	policy := func(resp *http.Response, err error, attempt int) (bool, time.Duration) {
+		return false, 0
+	}
+
+	client := NewClient(WithUrl(server.URL), WithRetry(3, time.Millisecond), WithRetryPolicy(policy))
+	_, _, err := client.Predict("michael")
+
+	assert.True(t, hasStatusCode(err, http.StatusTooManyRequests))
+	assert.Equal(t, 1, requests)
+}
+
+func TestShouldKeepErrorBodyAfterCustomPolicyReadsIt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"busy"}`))
+	}))
+	defer server.Close()
+
+	var seen string
+	policy := func(resp *http.Response, err error, attempt int) (bool, time.Duration) {
+		body, _ := io.ReadAll(resp.Body)
+		seen = string(body)
+		return false, 0
+	}
+
+	client := NewClient(WithUrl(server.URL), WithRetryPolicy(policy))
+	_, resp, err := client.PredictRaw(context.Background(), "michael")
+
+	assert.True(t, hasStatusCode(err, http.StatusServiceUnavailable))
+	assert.Equal(t, `{"error":"busy"}`, seen)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `{"error":"busy"}`, string(body))
+}
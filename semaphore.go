@@ -0,0 +1,40 @@
+package agify
+
+import "context"
+
+// semaphore limits the number of operations in flight across the client
+type semaphore struct {
+	slots chan struct{}
+}
+
+// newSemaphore creates a semaphore with n slots, or returns nil if n disables the limit
+func newSemaphore(n int) *semaphore {
+	if n <= 0 {
+		return nil
+	}
+
+	return &semaphore{slots: make(chan struct{}, n)}
+}
+
+// acquire takes a slot, waiting until one is free or the context is done
+func (sem *semaphore) acquire(ctx context.Context) error {
+	if sem == nil {
+		return nil
+	}
+
+	select {
+	case sem.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire
+func (sem *semaphore) release() {
+	if sem == nil {
+		return
+	}
+
+	<-sem.slots
+}
@@ -0,0 +1,74 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldCapRequestsInFlight(t *testing.T) {
+	var maxInFlight int32
+	server := httptest.NewServer(trackInFlight(&maxInFlight, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithGenderizeUrl(server.URL), WithMaxInFlight(5))
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			var err error
+
+			if i%2 == 0 {
+				_, _, err = client.Predict("michael")
+			} else {
+				_, _, err = client.Genderize("michael")
+			}
+
+			assert.Nil(t, err)
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(5), atomic.LoadInt32(&maxInFlight))
+}
+
+func TestShouldStopWaitingForInFlightSlotWhenCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(WithUrl(server.URL), WithMaxInFlight(1))
+	started := make(chan struct{})
+
+	go func() {
+		close(started)
+		client.Predict("michael")
+	}()
+
+	<-started
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := client.PredictContext(ctx, "matthew")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,22 @@
+package agify
+
+// Service is one of the APIs the client can call
+type Service int
+
+const (
+	// ServiceAgify is the agify.io age prediction API
+	ServiceAgify Service = iota
+	// ServiceGenderize is the genderize.io gender prediction API
+	ServiceGenderize
+	// ServiceNationalize is the nationalize.io nationality prediction API
+	ServiceNationalize
+)
+
+// defaultServiceUrls returns the public API URL for each service
+func defaultServiceUrls() map[Service]string {
+	return map[Service]string{
+		ServiceAgify:       "https://api.agify.io",
+		ServiceGenderize:   "https://api.genderize.io",
+		ServiceNationalize: "https://api.nationalize.io",
+	}
+}
@@ -0,0 +1,50 @@
+package agify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldUseDefaultServiceUrls(t *testing.T) {
+	client := NewClient()
+
+	assert.Equal(t, "https://api.agify.io", client.serviceUrls[ServiceAgify])
+	assert.Equal(t, "https://api.genderize.io", client.serviceUrls[ServiceGenderize])
+	assert.Equal(t, "https://api.nationalize.io", client.serviceUrls[ServiceNationalize])
+}
+
+func TestShouldOverrideEachServiceUrl(t *testing.T) {
+	var hits []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+
+		switch r.URL.Path {
+		case "/nationalize":
+			w.Write([]byte(`{"name":"michael","country":[]}`))
+		default:
+			w.Write([]byte(`{"name":"michael"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithServiceURL(ServiceAgify, server.URL+"/agify"),
+		WithServiceURL(ServiceGenderize, server.URL+"/genderize"),
+		WithServiceURL(ServiceNationalize, server.URL+"/nationalize"),
+	)
+
+	_, _, err := client.Predict("michael")
+	assert.Nil(t, err)
+
+	_, _, err = client.Genderize("michael")
+	assert.Nil(t, err)
+
+	_, _, err = client.Nationalize("michael")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"/agify", "/genderize", "/nationalize"}, hits)
+}
@@ -0,0 +1,174 @@
+package agify
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+type (
+	// PredictionResult is a prediction or the error that prevented it from a stream
+	PredictionResult struct {
+		// Prediction is the age prediction for a name
+		Prediction Prediction
+		// Err is the error from the request for the chunk of names
+		Err error
+	}
+
+	// chunkSource returns the next chunk of names, or nil when there are none left
+	chunkSource func() ([]string, error)
+
+	// chunkResult is the outcome of predicting a chunk of names
+	chunkResult struct {
+		predictions []Prediction
+		err         error
+	}
+)
+
+// PredictStream returns the age probability for a list of names as each chunk arrives
+// The channel is closed when every chunk has been sent, after the first error, or when the context is done.
+func (client *Client) PredictStream(ctx context.Context, names []string) (<-chan PredictionResult, error) {
+	names, err := client.validateNames(names)
+
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := chunkNames(names, client.chunkSize)
+	next := func() ([]string, error) {
+		if len(chunks) == 0 {
+			return nil, nil
+		}
+
+		chunk := chunks[0]
+		chunks = chunks[1:]
+
+		return chunk, nil
+	}
+
+	results := make(chan PredictionResult)
+	go client.streamChunks(ctx, next, 1, results)
+
+	return results, nil
+}
+
+// PredictReader returns the age probability for newline-delimited names read from r as each chunk arrives
+// Surrounding whitespace is trimmed and blank lines are skipped. Chunks are requested with the configured batch concurrency.
+// The channel is closed when the reader is exhausted, after the first error, or when the context is done.
+func (client *Client) PredictReader(ctx context.Context, r io.Reader) (<-chan PredictionResult, error) {
+	scanner := bufio.NewScanner(r)
+	size := client.chunkSize
+
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+
+	next := func() ([]string, error) {
+		var chunk []string
+
+		for len(chunk) < size && scanner.Scan() {
+			if name := strings.TrimSpace(scanner.Text()); name != "" {
+				chunk = append(chunk, name)
+			}
+		}
+
+		return chunk, scanner.Err()
+	}
+
+	results := make(chan PredictionResult)
+	go client.streamChunks(ctx, next, client.batchWorkers(), results)
+
+	return results, nil
+}
+
+// streamChunks predicts each chunk from next with up to workers requests in flight
+// Predictions are sent in the order of the chunks, and the results channel is closed when streaming stops.
+func (client *Client) streamChunks(ctx context.Context, next chunkSource, workers int, results chan<- PredictionResult) {
+	defer close(results)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	slots := make(chan struct{}, workers)
+	pending := make(chan chan chunkResult, workers)
+
+	go func() {
+		defer close(pending)
+
+		for {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			chunk, err := next()
+			done := make(chan chunkResult, 1)
+
+			if err != nil || len(chunk) == 0 {
+				<-slots
+
+				if err != nil {
+					done <- chunkResult{err: err}
+
+					select {
+					case pending <- done:
+					case <-ctx.Done():
+					}
+				}
+
+				return
+			}
+
+			select {
+			case pending <- done:
+			case <-ctx.Done():
+				return
+			}
+
+			go func() {
+				defer func() { <-slots }()
+
+				predictions, _, err := client.batchPredictSlot(ctx, client.normalizeNameList(chunk), "")
+
+				if err == nil {
+					predictions = client.restoreNameList(predictions, chunk)
+				}
+
+				done <- chunkResult{predictions: predictions, err: err}
+			}()
+		}
+	}()
+
+	for done := range pending {
+		var result chunkResult
+
+		select {
+		case result = <-done:
+		case <-ctx.Done():
+			return
+		}
+
+		if result.err != nil {
+			sendResult(ctx, results, PredictionResult{Err: result.err})
+			return
+		}
+
+		for _, prediction := range result.predictions {
+			if !sendResult(ctx, results, PredictionResult{Prediction: prediction}) {
+				return
+			}
+		}
+	}
+}
+
+// sendResult sends the result unless the context is done first
+func sendResult(ctx context.Context, results chan<- PredictionResult, result PredictionResult) bool {
+	select {
+	case results <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
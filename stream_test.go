@@ -0,0 +1,176 @@
+package agify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldStreamPredictions(t *testing.T) {
+	server := httptest.NewServer(batchHandler(t, nil))
+	defer server.Close()
+
+	names := makeNames(25)
+	client := NewClient(WithUrl(server.URL))
+	results, err := client.PredictStream(context.Background(), names)
+	assert.Nil(t, err)
+
+	count := 0
+
+	for result := range results {
+		assert.Nil(t, result.Err)
+		assert.Equal(t, names[count], result.Prediction.Name)
+		count++
+	}
+
+	assert.Equal(t, 25, count)
+}
+
+func TestShouldStreamChunkError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests == 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{ "error": "Request limit reached" }`))
+			return
+		}
+
+		batchHandler(t, nil)(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	results, err := client.PredictStream(context.Background(), makeNames(25))
+	assert.Nil(t, err)
+
+	var predictions int
+	var errs []error
+
+	for result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		} else {
+			predictions++
+		}
+	}
+
+	assert.Equal(t, 10, predictions)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, 2, requests)
+}
+
+func TestShouldStopStreamWhenContextCancelled(t *testing.T) {
+	server := httptest.NewServer(batchHandler(t, nil))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewClient(WithUrl(server.URL))
+	results, err := client.PredictStream(ctx, makeNames(25))
+	assert.Nil(t, err)
+
+	<-results
+	cancel()
+
+	for range results {
+	}
+}
+
+func TestShouldPredictNamesFromReader(t *testing.T) {
+	var requested []string
+	var mu sync.Mutex
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	input := "michael\n\n  matthew  \njane\n\n\nanna\nmark\n\tluke\njohn\npeter\npaul\nmary\n   \nsarah\nruth\n"
+
+	client := NewClient(WithUrl(server.URL))
+	results, err := client.PredictReader(context.Background(), strings.NewReader(input))
+	assert.Nil(t, err)
+
+	var names []string
+
+	for result := range results {
+		assert.Nil(t, result.Err)
+		names = append(names, result.Prediction.Name)
+	}
+
+	assert.Len(t, names, 12)
+	assert.Len(t, requested, 12)
+	assert.Equal(t, "matthew", names[1])
+	assert.Equal(t, "luke", names[5])
+	assert.Equal(t, "ruth", names[11])
+}
+
+func TestShouldLimitReaderConcurrency(t *testing.T) {
+	var maxInFlight int32
+	server := httptest.NewServer(inFlightHandler(t, &maxInFlight))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithConcurrency(2))
+	results, err := client.PredictReader(context.Background(), strings.NewReader(strings.Join(makeNames(60), "\n")))
+	assert.Nil(t, err)
+
+	count := 0
+
+	for result := range results {
+		assert.Nil(t, result.Err)
+		assert.Equal(t, fmt.Sprintf("name%d", count), result.Prediction.Name)
+		count++
+	}
+
+	assert.Equal(t, 60, count)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestShouldNormalizeStreamedNames(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNormalizeNames(true), WithRestoreNames(true))
+	results, err := client.PredictStream(context.Background(), []string{"  Michael ", "Jane"})
+	assert.Nil(t, err)
+
+	var names []string
+
+	for result := range results {
+		assert.Nil(t, result.Err)
+		names = append(names, result.Prediction.Name)
+	}
+
+	assert.Equal(t, []string{"michael", "jane"}, requested)
+	assert.Equal(t, []string{"Michael", "Jane"}, names)
+}
+
+func TestShouldNormalizeNamesFromReader(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNormalizeNames(true))
+	results, err := client.PredictReader(context.Background(), strings.NewReader("  Michael \nJANE\n"))
+	assert.Nil(t, err)
+
+	for result := range results {
+		assert.Nil(t, result.Err)
+	}
+
+	assert.Equal(t, []string{"michael", "jane"}, requested)
+}
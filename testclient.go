@@ -0,0 +1,124 @@
+package agify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type (
+	// TestFixture is the canned data served by a test client
+	TestFixture struct {
+		// Responses maps each name to the JSON prediction returned for it
+		Responses map[string]json.RawMessage `json:"responses"`
+		// Limit is sent as the X-Rate-Limit-Limit header
+		Limit int `json:"limit"`
+		// Remaining is sent as the X-Rate-Limit-Remaining header
+		Remaining int `json:"remaining"`
+		// Reset is sent as the X-Rate-Reset header in seconds
+		Reset int `json:"reset"`
+	}
+
+	// fixtureTransport serves requests from a handler in memory without touching the network
+	fixtureTransport struct {
+		handler http.Handler
+	}
+)
+
+// defaultTestRateLimit is the limit and remaining count sent by NewTestClient
+const defaultTestRateLimit = 1000
+
+// NewTestClient creates a client that answers predictions from canned JSON keyed by name, for testing code that uses the client offline
+// Names without a response are answered like agify.io answers an unknown name, with a null age.
+// The options are applied after the fixture's http client, so they should not replace it with WithClient.
+func NewTestClient(responses map[string]string, opts ...ClientOption) *Client {
+	raw := make(map[string]json.RawMessage, len(responses))
+
+	for name, response := range responses {
+		raw[name] = json.RawMessage(response)
+	}
+
+	return NewTestClientFromFixture(TestFixture{
+		Responses: raw,
+		Limit:     defaultTestRateLimit,
+		Remaining: defaultTestRateLimit,
+	}, opts...)
+}
+
+// NewTestClientFromFixture creates a client that answers predictions from the fixture, with its rate limit headers on every response
+func NewTestClientFromFixture(fixture TestFixture, opts ...ClientOption) *Client {
+	httpClient := &http.Client{Transport: &fixtureTransport{handler: fixture.handler()}}
+	return NewClient(append([]ClientOption{WithClient(httpClient)}, opts...)...)
+}
+
+// NewTestClientFromFile creates a test client from a JSON file holding a TestFixture
+func NewTestClientFromFile(path string, opts ...ClientOption) (*Client, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture TestFixture
+
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("agify: invalid test fixture %s: %w", path, err)
+	}
+
+	return NewTestClientFromFixture(fixture, opts...), nil
+}
+
+// RoundTrip serves the request from the handler
+func (transport *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	transport.handler.ServeHTTP(recorder, req)
+
+	return recorder.Result(), nil
+}
+
+// handler answers single and batch predictions from the fixture responses
+func (fixture TestFixture) handler() http.Handler {
+	params := ParamConfig{}.withDefaults()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Rate-Limit-Limit", strconv.Itoa(fixture.Limit))
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(fixture.Remaining))
+		w.Header().Set("X-Rate-Reset", strconv.Itoa(fixture.Reset))
+
+		query := r.URL.Query()
+
+		if names, ok := query[params.BatchName]; ok {
+			responses := make([]string, len(names))
+
+			for i, name := range names {
+				responses[i] = fixture.response(name)
+			}
+
+			w.Write([]byte("[" + strings.Join(responses, ",") + "]"))
+			return
+		}
+
+		if !query.Has(params.Name) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error":"Missing 'name' parameter"}`))
+			return
+		}
+
+		w.Write([]byte(fixture.response(query.Get(params.Name))))
+	})
+}
+
+// response returns the canned JSON for a name, or a prediction without an age if there is none
+func (fixture TestFixture) response(name string) string {
+	if response, ok := fixture.Responses[name]; ok {
+		return string(response)
+	}
+
+	unknown, _ := json.Marshal(map[string]any{"name": name, "age": nil, "count": 0})
+	return string(unknown)
+}
@@ -0,0 +1,72 @@
+package agify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldPredictFromTestClient(t *testing.T) {
+	client := NewTestClient(map[string]string{
+		"michael": `{"name":"michael","age":70,"count":875}`,
+	})
+
+	result, rateLimit, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Equal(t, 1000, rateLimit.Limit)
+	assert.Equal(t, 1000, rateLimit.Remaining)
+
+	result, _, err = client.Predict("unknown")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "unknown", result.Name)
+	assert.False(t, result.HasAge())
+}
+
+func TestShouldBatchPredictFromTestClient(t *testing.T) {
+	client := NewTestClient(map[string]string{
+		"michael": `{"name":"michael","age":70,"count":875}`,
+		"matthew": `{"name":"matthew","age":36,"count":100}`,
+	})
+
+	results, _, err := client.BatchPredict([]string{"matthew", "nobody", "michael"})
+
+	assert.Nil(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, 36, results[0].Age)
+	assert.False(t, results[1].HasAge())
+	assert.Equal(t, 70, results[2].Age)
+}
+
+func TestShouldLoadTestClientFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	fixture := `{
+		"responses": {"michael": {"name":"michael","age":70,"count":875}},
+		"limit": 100,
+		"remaining": 42,
+		"reset": 60
+	}`
+	assert.Nil(t, os.WriteFile(path, []byte(fixture), 0o600))
+
+	client, err := NewTestClientFromFile(path)
+	assert.Nil(t, err)
+
+	result, rateLimit, err := client.PredictContext(context.Background(), "michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+	assert.Equal(t, 100, rateLimit.Limit)
+	assert.Equal(t, 42, rateLimit.Remaining)
+	assert.Equal(t, time.Minute, rateLimit.Reset)
+}
+
+func TestShouldReturnErrorForMissingFixtureFile(t *testing.T) {
+	_, err := NewTestClientFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,31 @@
+package agify
+
+import (
+	"context"
+	"time"
+)
+
+// TimestampedPrediction is a prediction along with the time it was fetched, for storing alongside its freshness
+type TimestampedPrediction struct {
+	Prediction
+	// FetchedAt is the time the response was received, or the time a cached prediction was served
+	FetchedAt time.Time
+}
+
+// PredictTimestamped returns the age probability for a name stamped with the time it was fetched using the client clock
+func (client *Client) PredictTimestamped(ctx context.Context, name string) (*TimestampedPrediction, *RateLimit, error) {
+	prediction, meta, err := client.PredictWithMeta(ctx, name, "")
+	rateLimit := meta.rateLimitOrNil()
+
+	if err != nil || prediction == nil {
+		return nil, rateLimit, err
+	}
+
+	fetchedAt := client.clock.Now()
+
+	if meta != nil {
+		fetchedAt = meta.receivedAt
+	}
+
+	return &TimestampedPrediction{Prediction: *prediction, FetchedAt: fetchedAt}, rateLimit, nil
+}
@@ -0,0 +1,48 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldStampPredictionWithFetchTime(t *testing.T) {
+	fetchedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := newFakeClock(fetchedAt.Add(-time.Second))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.Advance(time.Second)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithClock(clock))
+	prediction, _, err := client.PredictTimestamped(context.Background(), "michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "michael", prediction.Name)
+	assert.Equal(t, 70, prediction.Age)
+	assert.Equal(t, fetchedAt, prediction.FetchedAt)
+}
+
+func TestShouldStampCachedPredictionWhenServed(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(countingHandler(&requests))
+	defer server.Close()
+
+	clock := newFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	client := NewClient(WithUrl(server.URL), WithClock(clock), WithCache(time.Hour))
+
+	client.PredictTimestamped(context.Background(), "michael")
+	clock.Advance(time.Minute)
+	prediction, _, err := client.PredictTimestamped(context.Background(), "michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, clock.Now(), prediction.FetchedAt)
+}
@@ -0,0 +1,38 @@
+package agify
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name used for spans
+const tracerName = "github.com/masonkmeyer/agify"
+
+// startSpan starts a span for a request with the number of names and the country
+func (client *Client) startSpan(ctx context.Context, spanName string, names int, country string) (context.Context, trace.Span) {
+	return client.tracer.Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int("agify.name_count", names),
+			attribute.String("agify.country", country),
+		),
+	)
+}
+
+// setSpanStatusCode records the HTTP status code on the span in the context
+func setSpanStatusCode(ctx context.Context, statusCode int) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("http.response.status_code", statusCode))
+}
+
+// endSpan records the error, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
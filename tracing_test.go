@@ -0,0 +1,95 @@
+package agify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanAttributes converts the span attributes into a map
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value)
+
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+
+	return attributes
+}
+
+func TestShouldRecordSpanForPrediction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875,"country_id":"US"}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := NewClient(WithUrl(server.URL), WithTracerProvider(provider))
+	_, _, err := client.PredictWithCountry("michael", "US")
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "agify.Predict", spans[0].Name())
+
+	attributes := spanAttributes(spans[0])
+	assert.Equal(t, int64(1), attributes["agify.name_count"].AsInt64())
+	assert.Equal(t, "US", attributes["agify.country"].AsString())
+	assert.Equal(t, int64(http.StatusOK), attributes["http.response.status_code"].AsInt64())
+}
+
+func TestShouldRecordSpanErrorForBatchPrediction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{ "error": "Invalid API key" }`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := NewClient(WithUrl(server.URL), WithTracerProvider(provider))
+	_, _, err := client.BatchPredict([]string{"michael", "jane"})
+	assert.NotNil(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "agify.BatchPredict", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	attributes := spanAttributes(spans[0])
+	assert.Equal(t, int64(2), attributes["agify.name_count"].AsInt64())
+	assert.Equal(t, int64(http.StatusUnauthorized), attributes["http.response.status_code"].AsInt64())
+}
+
+func TestShouldRecordSpansForProbes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := NewClient(WithUrl(server.URL), WithTracerProvider(provider))
+	assert.Nil(t, client.Ping(context.Background()))
+
+	_, err := client.QuotaStatus(context.Background())
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "agify.Ping", spans[0].Name())
+	assert.Equal(t, "agify.QuotaStatus", spans[1].Name())
+}
@@ -0,0 +1,107 @@
+package agify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// transportTuning holds the connection pool limits set by WithTransportTuning
+type transportTuning struct {
+	maxIdleConns    int
+	maxConnsPerHost int
+	idleTimeout     time.Duration
+}
+
+// configureHttpClient applies the redirect policy and transport options to a copy of the http client
+func (defaults *clientDefaults) configureHttpClient() (*http.Client, error) {
+	httpClient := defaults.withRedirectPolicy(defaults.http)
+
+	if defaults.proxyUrl == "" && defaults.transportTuning == nil && defaults.tlsConfig == nil && defaults.network == "" {
+		return httpClient, nil
+	}
+
+	switch defaults.network {
+	case "", "tcp", "tcp4", "tcp6":
+	default:
+		return httpClient, fmt.Errorf("agify: invalid network %q: must be tcp, tcp4 or tcp6", defaults.network)
+	}
+
+	if tlsConfig := defaults.tlsConfig; tlsConfig != nil && tlsConfig.MaxVersion != 0 && tlsConfig.MinVersion > tlsConfig.MaxVersion {
+		return httpClient, errors.New("agify: invalid tls config: minimum version is above maximum version")
+	}
+
+	var proxyUrl *url.URL
+
+	if defaults.proxyUrl != "" {
+		var err error
+		proxyUrl, err = url.Parse(defaults.proxyUrl)
+
+		if err == nil && proxyUrl.Host == "" {
+			err = fmt.Errorf("missing host in %q", defaults.proxyUrl)
+		}
+
+		if err != nil {
+			return httpClient, fmt.Errorf("agify: invalid proxy url: %w", err)
+		}
+	}
+
+	return withTransport(httpClient, func(transport *http.Transport) {
+		if proxyUrl != nil {
+			transport.Proxy = http.ProxyURL(proxyUrl)
+		}
+
+		if tuning := defaults.transportTuning; tuning != nil {
+			transport.MaxIdleConns = tuning.maxIdleConns
+			transport.MaxIdleConnsPerHost = tuning.maxConnsPerHost
+			transport.MaxConnsPerHost = tuning.maxConnsPerHost
+			transport.IdleConnTimeout = tuning.idleTimeout
+		}
+
+		// A custom TLS config disables HTTP/2 unless it is explicitly attempted
+		if defaults.tlsConfig != nil {
+			transport.TLSClientConfig = defaults.tlsConfig.Clone()
+			transport.ForceAttemptHTTP2 = true
+		}
+
+		if defaults.network != "" {
+			transport.DialContext = withNetwork(transport.DialContext, defaults.network)
+		}
+	})
+}
+
+// withTransport returns a copy of the http client with a cloned transport modified by configure
+func withTransport(httpClient *http.Client, configure func(*http.Transport)) (*http.Client, error) {
+	var transport *http.Transport
+
+	switch current := httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = current.Clone()
+	default:
+		return httpClient, fmt.Errorf("agify: cannot configure transport of type %T", current)
+	}
+
+	configure(transport)
+
+	clone := *httpClient
+	clone.Transport = transport
+
+	return &clone, nil
+}
+
+// withNetwork wraps the dial function to always dial the network, such as tcp4 to force IPv4
+func withNetwork(dial func(ctx context.Context, network string, addr string) (net.Conn, error), network string) func(ctx context.Context, network string, addr string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	return func(ctx context.Context, _ string, addr string) (net.Conn, error) {
+		return dial(ctx, network, addr)
+	}
+}
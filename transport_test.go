@@ -0,0 +1,136 @@
+package agify
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldRouteRequestsThroughProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "api.agify.test", r.URL.Host)
+		assert.Equal(t, "michael", r.URL.Query().Get("name"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer proxy.Close()
+
+	client := NewClient(WithUrl("http://api.agify.test"), WithProxy(proxy.URL))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+}
+
+func TestShouldCloneTransportForProxy(t *testing.T) {
+	transport := &http.Transport{MaxIdleConns: 7}
+	httpClient := &http.Client{Transport: transport, Timeout: time.Second}
+
+	client := NewClient(WithClient(httpClient), WithProxy("http://proxy.test:8080"))
+	configured := client.http.Transport.(*http.Transport)
+
+	assert.Nil(t, transport.Proxy)
+	assert.NotSame(t, transport, configured)
+	assert.Equal(t, 7, configured.MaxIdleConns)
+	assert.Equal(t, time.Second, client.http.Timeout)
+	assert.NotNil(t, configured.Proxy)
+}
+
+func TestShouldReturnInvalidProxyOnFirstRequest(t *testing.T) {
+	client := NewClient(WithProxy("://bad"))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "invalid proxy url")
+}
+
+func TestShouldTuneTransport(t *testing.T) {
+	transport := &http.Transport{TLSHandshakeTimeout: 3 * time.Second}
+	client := NewClient(WithClient(&http.Client{Transport: transport}), WithTransportTuning(50, 20, time.Minute))
+	configured := client.http.Transport.(*http.Transport)
+
+	assert.NotSame(t, transport, configured)
+	assert.Equal(t, 50, configured.MaxIdleConns)
+	assert.Equal(t, 20, configured.MaxConnsPerHost)
+	assert.Equal(t, 20, configured.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, configured.IdleConnTimeout)
+	assert.Equal(t, 3*time.Second, configured.TLSHandshakeTimeout)
+	assert.Equal(t, 0, transport.MaxIdleConns)
+}
+
+func TestShouldTuneTransportWithProxy(t *testing.T) {
+	client := NewClient(WithProxy("http://proxy.test:8080"), WithTransportTuning(10, 5, time.Second))
+	configured := client.http.Transport.(*http.Transport)
+
+	assert.NotNil(t, configured.Proxy)
+	assert.Equal(t, 5, configured.MaxConnsPerHost)
+}
+
+func TestShouldNegotiateTLS13AndHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	client := NewClient(WithUrl(server.URL), WithTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS13}))
+	_, resp, err := client.PredictRaw(context.Background(), "michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, resp.ProtoMajor)
+	assert.Equal(t, uint16(tls.VersionTLS13), resp.TLS.Version)
+}
+
+func TestShouldReturnInvalidTLSConfigOnFirstRequest(t *testing.T) {
+	client := NewClient(WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13, MaxVersion: tls.VersionTLS12}))
+	_, _, err := client.Predict("michael")
+
+	assert.ErrorContains(t, err, "invalid tls config")
+}
+
+func TestShouldForceNetworkForDial(t *testing.T) {
+	server := httptest.NewServer(countingHandler(new(int)))
+	defer server.Close()
+
+	var networks []string
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+			networks = append(networks, network)
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+
+	client := NewClient(WithUrl(server.URL), WithClient(&http.Client{Transport: transport}), WithNetwork("tcp4"))
+	_, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tcp4"}, networks)
+	assert.NotSame(t, transport, client.http.Transport)
+}
+
+func TestShouldKeepDualStackByDefault(t *testing.T) {
+	transport := &http.Transport{}
+	client := NewClient(WithClient(&http.Client{Transport: transport}))
+
+	assert.Same(t, transport, client.http.Transport)
+}
+
+func TestShouldReturnInvalidNetworkOnFirstRequest(t *testing.T) {
+	client := NewClient(WithNetwork("udp"))
+	_, _, err := client.Predict("michael")
+
+	assert.EqualError(t, err, `agify: invalid network "udp": must be tcp, tcp4 or tcp6`)
+}
@@ -0,0 +1,121 @@
+package agify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NoCountry can be passed as the country to send a request without a country when a default country is configured
+const NoCountry = "-"
+
+// BuildURL returns the URL a prediction for a name in a country would request, including the API key
+func (client *Client) BuildURL(name string, country string) (string, error) {
+	url, err := client.predictUrl(name, country)
+
+	if err != nil {
+		return "", err
+	}
+
+	return client.withNextApiKey(url), nil
+}
+
+// BuildBatchURL returns the URL a batch prediction for names in a country would request, including the API key
+// The names are not chunked, so callers should pass at most one chunk of names.
+func (client *Client) BuildBatchURL(names []string, country string) (string, error) {
+	url, err := client.batchUrl(names, country)
+
+	if err != nil {
+		return "", err
+	}
+
+	return client.withNextApiKey(url), nil
+}
+
+// predictUrl builds the URL for a single prediction without the API key
+// Query parameters in the base URL are kept, except for the parameters the request sets.
+func (client *Client) predictUrl(name string, country string) (string, error) {
+	url, err := parseBaseUrl(client.serviceUrls[ServiceAgify])
+
+	if err != nil {
+		return "", err
+	}
+
+	country, err = client.resolveCountry(country)
+
+	if err != nil {
+		return "", err
+	}
+
+	values := url.Query()
+
+	values.Set(client.params.Name, name)
+
+	if country != "" {
+		values.Set(client.params.Country, country)
+	}
+
+	url.RawQuery = values.Encode()
+
+	return url.String(), nil
+}
+
+// batchUrl builds the URL for a batch prediction without the API key
+// Query parameters in the base URL are kept, except for the parameters the request sets.
+func (client *Client) batchUrl(names []string, country string) (string, error) {
+	url, err := parseBaseUrl(client.serviceUrls[ServiceAgify])
+
+	if err != nil {
+		return "", err
+	}
+
+	country, err = client.resolveCountry(country)
+
+	if err != nil {
+		return "", err
+	}
+
+	values := url.Query()
+
+	values.Set(client.params.Country, country)
+	values.Del(client.params.BatchName)
+
+	for _, name := range names {
+		values.Add(client.params.BatchName, name)
+	}
+
+	url.RawQuery = values.Encode()
+
+	return url.String(), nil
+}
+
+// resolveCountry returns the country to send for a request, validating it if enabled
+// An explicit country is used as is, an empty country falls back to the default country, and NoCountry is sent as no country.
+func (client *Client) resolveCountry(country string) (string, error) {
+	switch country {
+	case NoCountry:
+		return "", nil
+	case "":
+		country = client.defaultCountry
+	}
+
+	if !client.validateCountry || country == "" {
+		return country, nil
+	}
+
+	if !isCountryCode(country) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCountry, country)
+	}
+
+	return strings.ToUpper(country), nil
+}
+
+// withNextApiKey adds the API key the next request would use to the URL
+func (client *Client) withNextApiKey(url string) string {
+	apiKey := client.apiKeys.peek()
+
+	if apiKey == "" {
+		return url
+	}
+
+	return withApiKey(url, client.params.ApiKey, apiKey)
+}
@@ -0,0 +1,231 @@
+package agify
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldBuildPredictionUrl(t *testing.T) {
+	client := NewClient(WithApiKey("key"))
+	url, err := client.BuildURL("michael", "US")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.agify.io?apikey=key&country_id=US&name=michael", url)
+}
+
+func TestShouldBuildBatchUrl(t *testing.T) {
+	client := NewClient(WithUrl("https://agify.example.com/v1"), WithApiKey("key"))
+
+	first, err := client.BuildBatchURL([]string{"michael", "matthew", "jane"}, "US")
+	assert.Nil(t, err)
+
+	second, err := client.BuildBatchURL([]string{"michael", "matthew", "jane"}, "US")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "https://agify.example.com/v1?apikey=key&country_id=US&name%5B%5D=michael&name%5B%5D=matthew&name%5B%5D=jane", first)
+	assert.Equal(t, first, second)
+}
+
+func TestShouldBuildUrlWithoutApiKey(t *testing.T) {
+	client := NewClient()
+	url, err := client.BuildURL("michael", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.agify.io?name=michael", url)
+}
+
+func TestShouldNotAdvanceApiKeyRotationWhenBuildingUrl(t *testing.T) {
+	client := NewClient(WithApiKeys("a", "b"))
+	client.BuildURL("michael", "")
+
+	assert.Equal(t, "a", client.apiKeys.next())
+}
+
+func TestShouldSkipRateLimitedApiKeyWhenBuildingUrl(t *testing.T) {
+	client := NewClient(WithApiKeys("a", "b"), WithSkipLimitedApiKeys(true))
+	client.apiKeys.markLimited("a", time.Minute)
+
+	url, err := client.BuildURL("michael", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.agify.io?apikey=b&name=michael", url)
+	assert.Equal(t, "b", client.apiKeys.next())
+}
+
+func TestShouldReturnErrorBuildingInvalidUrl(t *testing.T) {
+	client := NewClient(WithUrl("://bad"))
+	_, err := client.BuildURL("michael", "")
+
+	assert.ErrorContains(t, err, "invalid base url")
+}
+
+func TestShouldApplyDefaultCountry(t *testing.T) {
+	var countries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countries = append(countries, r.URL.Query().Get("country_id"))
+		w.WriteHeader(http.StatusOK)
+
+		if r.URL.Query().Has("name[]") {
+			w.Write([]byte(`[{"name":"michael","age":70,"count":875}]`))
+			return
+		}
+
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithDefaultCountry("US"))
+
+	client.Predict("michael")
+	client.PredictWithCountry("michael", "GB")
+	client.PredictWithCountry("michael", "")
+	client.PredictWithCountry("michael", NoCountry)
+	client.BatchPredict([]string{"michael"})
+	client.BatchPredictWithCountry([]string{"michael"}, "DE")
+
+	assert.Equal(t, []string{"US", "GB", "US", "", "US", "DE"}, countries)
+}
+
+func TestShouldBuildURLWithoutCountryByDefault(t *testing.T) {
+	client := NewClient(WithUrl("http://example.test"))
+	url, err := client.BuildURL("michael", NoCountry)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://example.test?name=michael", url)
+}
+
+func TestShouldBuildSameUrlAcrossOptionOrders(t *testing.T) {
+	first := NewClient(WithApiKey("key"), WithDefaultCountry("US"), WithParamNames(ParamConfig{Name: "n"}))
+	second := NewClient(WithParamNames(ParamConfig{Name: "n"}), WithDefaultCountry("US"), WithApiKey("key"))
+
+	firstUrl, err := first.BuildURL("michael", "")
+	assert.Nil(t, err)
+
+	secondUrl, err := second.BuildURL("michael", "")
+	assert.Nil(t, err)
+
+	assert.Equal(t, firstUrl, secondUrl)
+	assert.Equal(t, "https://api.agify.io?apikey=key&country_id=US&n=michael", firstUrl)
+}
+
+// unicodeNames are names that need percent-encoding in a query string
+var unicodeNames = []string{"José", "O'Brien", "Mary Ann", "Zoë", "Łukasz", "小明", "Anne-Marie & Co"}
+
+func TestShouldEncodeUnicodeNameInUrl(t *testing.T) {
+	client := NewClient()
+	url, err := client.BuildURL("José", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.agify.io?name=Jos%C3%A9", url)
+
+	url, err = client.BuildURL("O'Brien", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.agify.io?name=O%27Brien", url)
+}
+
+func TestShouldSendUnicodeNamesToServer(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		received = append(received, name)
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(fmt.Sprintf(`{"name":%q,"age":40,"count":10}`, name)))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+
+	for _, name := range unicodeNames {
+		result, _, err := client.Predict(name)
+
+		assert.Nil(t, err)
+		assert.Equal(t, name, result.Name)
+	}
+
+	assert.Equal(t, unicodeNames, received)
+}
+
+func TestShouldSendUnicodeBatchNamesToServer(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		received = append(received, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithChunkSize(len(unicodeNames)))
+	results, _, err := client.BatchPredict(unicodeNames)
+
+	assert.Nil(t, err)
+	assert.Equal(t, unicodeNames, received)
+
+	for i, result := range results {
+		assert.Equal(t, unicodeNames[i], result.Name)
+		assert.True(t, result.HasAge())
+	}
+}
+
+func TestShouldNormalizeUnicodeNames(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		received = append(received, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithNormalizeNames(true), WithRestoreNames(true))
+	results, _, err := client.BatchPredict([]string{" JOSÉ ", "ŁUKASZ"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"josé", "łukasz"}, received)
+	assert.Equal(t, "JOSÉ", results[0].Name)
+	assert.Equal(t, "ŁUKASZ", results[1].Name)
+}
+
+func TestShouldKeepBaseUrlQueryParams(t *testing.T) {
+	client := NewClient(WithUrl("https://host/path?x=1"))
+	url, err := client.BuildURL("michael", "US")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://host/path?country_id=US&name=michael&x=1", url)
+
+	url, err = client.BuildBatchURL([]string{"michael", "jane"}, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://host/path?country_id=&name%5B%5D=michael&name%5B%5D=jane&x=1", url)
+}
+
+func TestShouldReplaceRequestParamsInBaseUrl(t *testing.T) {
+	client := NewClient(WithUrl("https://host/path?name=stale&name%5B%5D=stale&country_id=GB&x=1"))
+	url, err := client.BuildURL("michael", "US")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://host/path?country_id=US&name=michael&name%5B%5D=stale&x=1", url)
+
+	url, err = client.BuildBatchURL([]string{"michael"}, "US")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://host/path?country_id=US&name=stale&name%5B%5D=michael&x=1", url)
+}
+
+func TestShouldRequestBaseUrlWithPathAndQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/agify/v1", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("x"))
+		assert.Equal(t, "michael", r.URL.Query().Get("name"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"name":"michael","age":70,"count":875}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL + "/agify/v1?x=1"))
+	result, _, err := client.Predict("michael")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 70, result.Age)
+}
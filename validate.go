@@ -0,0 +1,58 @@
+package agify
+
+import (
+	"errors"
+	"strings"
+)
+
+// validateName returns ErrEmptyName if the name is empty after trimming whitespace
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrEmptyName
+	}
+
+	return nil
+}
+
+// validateNames checks every name in a batch
+// Empty names are either removed or reject the whole batch depending on the client configuration.
+func (client *Client) validateNames(names []string) ([]string, error) {
+	valid := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if err := validateName(name); err != nil {
+			if client.skipEmpty {
+				continue
+			}
+
+			return nil, err
+		}
+
+		valid = append(valid, name)
+	}
+
+	return valid, nil
+}
+
+// validate checks the client configuration for invalid options
+func (client *Client) validate() error {
+	if client.configErr != nil {
+		return client.configErr
+	}
+
+	for _, rawUrl := range client.serviceUrls {
+		if _, err := parseBaseUrl(rawUrl); err != nil {
+			return err
+		}
+	}
+
+	if client.timeout < 0 {
+		return errors.New("agify: timeout must not be negative")
+	}
+
+	if client.chunkSize <= 0 {
+		return errors.New("agify: chunk size must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package agify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldRejectEmptyNames(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+
+	for _, name := range []string{"", "   "} {
+		result, rateLimit, err := client.Predict(name)
+
+		assert.Nil(t, result)
+		assert.Nil(t, rateLimit)
+		assert.True(t, errors.Is(err, ErrEmptyName))
+	}
+
+	assert.Equal(t, 0, requests)
+}
+
+func TestShouldRejectBatchWithEmptyName(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL))
+	result, _, err := client.BatchPredict([]string{"michael", " ", "jane"})
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, ErrEmptyName))
+	assert.Equal(t, 0, requests)
+}
+
+func TestShouldSkipEmptyNamesInBatch(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(batchHandler(t, func(names []string) {
+		requested = append(requested, names...)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithUrl(server.URL), WithSkipEmpty(true))
+	result, _, err := client.BatchPredict([]string{"michael", " ", "jane"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"michael", "jane"}, requested)
+	assert.Len(t, result, 2)
+}
+
+func TestShouldCreateStrictClient(t *testing.T) {
+	client, err := NewClientStrict(WithUrl("https://agify.example.com"), WithTimeout(time.Second), WithChunkSize(5))
+
+	assert.Nil(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestShouldRejectInvalidStrictClientConfiguration(t *testing.T) {
+	tests := map[string]struct {
+		opts    []ClientOption
+		message string
+	}{
+		"base url":   {[]ClientOption{WithUrl("://bad")}, "invalid base url"},
+		"proxy url":  {[]ClientOption{WithProxy("://bad")}, "invalid proxy url"},
+		"timeout":    {[]ClientOption{WithTimeout(-time.Second)}, "timeout must not be negative"},
+		"chunk size": {[]ClientOption{WithChunkSize(0)}, "chunk size must be positive"},
+	}
+
+	for name, test := range tests {
+		client, err := NewClientStrict(test.opts...)
+
+		assert.Nil(t, client, name)
+		assert.ErrorContains(t, err, test.message, name)
+	}
+}